@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	gcal "google.golang.org/api/calendar/v3"
+	"virtual-assistant/internal/calendar"
+	"virtual-assistant/internal/config"
+	"virtual-assistant/internal/dateparse"
+	"virtual-assistant/internal/timezone"
+)
+
+// authorize runs the Google authorization flow and saves the new token over
+// the old one, then checks that it works. A running assistant keeps using
+// the token it started with until it's restarted. It returns the exit code.
+func authorize(cfg *config.Config) int {
+	if err := setup(cfg); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := calendar.Authorize(cfg.Storage.GoogleCredentials, cfg.Storage.GoogleToken, cfg.Server.OAuthPort); err != nil {
+		fmt.Fprintf(os.Stderr, "authorization failed: %v\n", err)
+		return 1
+	}
+
+	calendarService, err := calendar.Connect(cfg.Storage.GoogleCredentials, cfg.Storage.GoogleToken)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to use the new token: %v\n", err)
+		return 1
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+	defer cancel()
+	detail, err := calendarService.Health(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ The new token doesn't work: %v\n", err)
+		return 1
+	}
+	fmt.Printf("✅ Google Calendar authorized: %s\n", detail)
+	return 0
+}
+
+// printAgenda prints the events of the primary calendar on the day given as
+// YYYY-MM-DD or in words ("tomorrow", "jumat"), today if it's empty. It
+// returns the exit code.
+func printAgenda(cfg *config.Config, date string) int {
+	if err := setup(cfg); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	day, err := agendaDay(date, time.Now().In(timezone.Location()))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	if err := calendar.CheckCredentials(cfg.Storage.GoogleCredentials); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	calendarService, err := calendar.Connect(cfg.Storage.GoogleCredentials, cfg.Storage.GoogleToken)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v; run `auth` first\n", err)
+		return 1
+	}
+	events, err := calendarService.GetEventsOn(nil, day)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	fmt.Printf("📅 %s (%s)\n", day.Format("Monday 02 January 2006"), timezone.Location())
+	if len(events) == 0 {
+		fmt.Println("  No events")
+	}
+	for _, event := range events {
+		fmt.Printf("  %-12s %s\n", eventHours(event), event.Summary)
+	}
+	return 0
+}
+
+// agendaDay reads the date given to agenda.
+func agendaDay(date string, now time.Time) (time.Time, error) {
+	if date == "" {
+		return now, nil
+	}
+	if day, err := time.ParseInLocation("2006-01-02", date, now.Location()); err == nil {
+		return day, nil
+	}
+	if parsed := dateparse.Parse(date, now); parsed.HasDate && strings.TrimSpace(parsed.Rest) == "" {
+		return parsed.Date, nil
+	}
+	return time.Time{}, fmt.Errorf("can't read %q as a date; use YYYY-MM-DD, today, tomorrow or a weekday", date)
+}
+
+// eventHours renders when an event runs, e.g. "09:00-09:30" or "all day".
+func eventHours(event *gcal.Event) string {
+	if calendar.IsAllDay(event) {
+		return "all day"
+	}
+	start, err := time.Parse(time.RFC3339, event.Start.DateTime)
+	if err != nil {
+		return event.Start.DateTime
+	}
+	hours := start.In(timezone.Location()).Format("15:04")
+	if end, err := time.Parse(time.RFC3339, event.End.DateTime); err == nil {
+		hours += "-" + end.In(timezone.Location()).Format("15:04")
+	}
+	return hours
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"virtual-assistant/internal/config"
+	"virtual-assistant/internal/llm"
+)
+
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), `Usage: %s [flags] [command]
+
+Commands:
+  serve                     run the assistant (the default)
+  auth                      authorize Google Calendar again, replacing the saved token
+  agenda [date]             print the events of a day, today by default
+  send-test-reminder <chat> send a chat a sample meeting reminder
+  doctor                    check the config, claude, Google and Telegram
+  webhook set|delete|info   register, remove or describe the Telegram webhook
+  config check              print the effective config with secrets masked and check it
+  keys generate             print a new storage encryption key
+  keys rotate               re-encrypt the storage files with the current key
+
+Flags:
+`, os.Args[0])
+	flag.PrintDefaults()
+}
+
+// checkConfig prints the config as it would be used, after the file, profile
+// and environment are applied, then lists whatever is invalid. It returns the
+// exit code.
+func checkConfig(cfg *config.Config) int {
+	out, err := cfg.Masked()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to print config: %v\n", err)
+		return 1
+	}
+	fmt.Printf("# Effective config (%s)\n%s", configSource(cfg), out)
+
+	if err := validate(cfg); err != nil {
+		fmt.Fprintln(os.Stderr, "\n❌ Config is invalid:")
+		for _, line := range strings.Split(err.Error(), "\n") {
+			fmt.Fprintf(os.Stderr, "  - %s\n", line)
+		}
+		return 1
+	}
+	fmt.Fprintln(os.Stderr, "\n✅ Config is valid")
+	return 0
+}
+
+// validate is cfg.Validate plus the checks the config package leaves to the
+// packages that own the setting, such as parsing the prompt instructions.
+func validate(cfg *config.Config) error {
+	err := cfg.Validate()
+	if _, tmplErr := llm.ParseInstructions(cfg.LLM.Instructions); tmplErr != nil {
+		err = errors.Join(err, fmt.Errorf("llm.instructions (LLM_INSTRUCTIONS): %v", tmplErr))
+	}
+	return err
+}
+
+// configSource says where the config came from.
+func configSource(cfg *config.Config) string {
+	if cfg.File == "" {
+		return "defaults and environment only"
+	}
+	if cfg.Profile != "" {
+		return cfg.File + ", profile " + cfg.Profile
+	}
+	return cfg.File
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"virtual-assistant/internal/bot"
+	"virtual-assistant/internal/calendar"
+	"virtual-assistant/internal/config"
+	"virtual-assistant/internal/llm"
+	"virtual-assistant/internal/storage"
+)
+
+// How long each of doctor's checks may take
+const checkTimeout = 20 * time.Second
+
+// doctor checks everything the assistant needs before it can run: the
+// config, the claude binary, the Google credentials and token, the database
+// and the Telegram bot and webhook. It returns the exit code.
+func doctor(cfg *config.Config) int {
+	code := 0
+	report := func(name, detail string, err error) {
+		if err != nil {
+			fmt.Printf("❌ %-19s %v\n", name, err)
+			code = 1
+		} else {
+			fmt.Printf("✅ %-19s %s\n", name, detail)
+		}
+	}
+	warn := func(name, detail string) {
+		fmt.Printf("⚠️  %-19s %s\n", name, detail)
+	}
+	ctx := func() (context.Context, context.CancelFunc) {
+		return context.WithTimeout(context.Background(), checkTimeout)
+	}
+
+	if err := setup(cfg); err != nil {
+		report("config", "", err)
+	} else if err := validate(cfg); err != nil {
+		report("config", "", errors.New(strings.ReplaceAll(err.Error(), "\n", "; ")))
+	} else {
+		report("config", configSource(cfg), nil)
+	}
+
+	claudeService, err := llm.NewClaudeCodeService(cfg.LLM.Path)
+	if err == nil {
+		c, cancel := ctx()
+		var version string
+		version, err = claudeService.Health(c)
+		cancel()
+		report("claude", version, err)
+	} else {
+		report("claude", "", err)
+	}
+
+	if err := calendar.CheckCredentials(cfg.Storage.GoogleCredentials); err != nil {
+		report("google credentials", "", err)
+	} else {
+		report("google credentials", cfg.Storage.GoogleCredentials, nil)
+		calendarService, err := calendar.Connect(cfg.Storage.GoogleCredentials, cfg.Storage.GoogleToken)
+		if err != nil {
+			report("google token", "", fmt.Errorf("%v; run `auth`", err))
+		} else {
+			c, cancel := ctx()
+			detail, err := calendarService.Health(c)
+			cancel()
+			if err != nil {
+				err = fmt.Errorf("%v; run `auth` if it was revoked", err)
+			}
+			report("google token", detail, err)
+		}
+	}
+
+	// Opening a missing database would create it
+	var db *storage.DB
+	_, err = os.Stat(cfg.Storage.Database)
+	if err == nil {
+		db, err = storage.Open(cfg.Storage.Database)
+	}
+	switch {
+	case os.IsNotExist(err):
+		warn("storage", cfg.Storage.Database+" doesn't exist yet; it's created on first start")
+	case errors.Is(err, storage.ErrInUse):
+		warn("storage", err.Error()+"; the assistant is probably running")
+	case err != nil:
+		report("storage", "", err)
+	default:
+		c, cancel := ctx()
+		detail, err := db.Health(c)
+		cancel()
+		db.Close()
+		report("storage", detail, err)
+	}
+
+	if cfg.Telegram.Token == "" {
+		report("telegram", "", errors.New("no bot token"))
+		return code
+	}
+	telegramBot, err := bot.NewTelegramBot(cfg.Telegram.Token, cfg.Telegram.WebhookURL, nil, nil, nil, nil, nil)
+	if err != nil {
+		report("telegram", "", err)
+		return code
+	}
+	report("telegram", "@"+telegramBot.Username(), nil)
+
+	info, err := telegramBot.WebhookInfo()
+	switch {
+	case err != nil:
+		report("webhook", "", err)
+	case cfg.Telegram.WebhookURL == "" && info.URL != "":
+		report("webhook", "", fmt.Errorf("set to %s, so polling gets no updates; run `webhook delete`", info.URL))
+	case cfg.Telegram.WebhookURL == "":
+		report("webhook", "none, polling", nil)
+	case info.URL != cfg.Telegram.WebhookURL+"/webhook":
+		warn("webhook", fmt.Sprintf("not set to %s/webhook yet; the assistant sets it on start", cfg.Telegram.WebhookURL))
+	case info.LastErrorDate != 0 && time.Since(time.Unix(int64(info.LastErrorDate), 0)) < time.Hour:
+		warn("webhook", fmt.Sprintf("%s, last delivery error %s ago: %s", info.URL, time.Since(time.Unix(int64(info.LastErrorDate), 0)).Round(time.Second), info.LastErrorMessage))
+	default:
+		report("webhook", fmt.Sprintf("%s, %d pending", info.URL, info.PendingUpdateCount), nil)
+	}
+	return code
+}
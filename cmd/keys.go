@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"virtual-assistant/internal/config"
+	"virtual-assistant/internal/crypt"
+	"virtual-assistant/internal/storage"
+)
+
+// generateKey prints a new encryption key. It returns the exit code.
+func generateKey() int {
+	key, err := crypt.GenerateKey()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to generate key: %v\n", err)
+		return 1
+	}
+	fmt.Println(key)
+	return 0
+}
+
+// rotateKeys re-encrypts every storage file and the database with the
+// current key, reading what was written with an old key or in plaintext.
+// The assistant must be stopped, since it holds the database open; if it
+// isn't, nothing is touched. Once it succeeds the old keys can be dropped
+// from the config. It returns the exit code.
+func rotateKeys(cfg *config.Config) int {
+	if cfg.Storage.EncryptionKey == "" {
+		fmt.Fprintln(os.Stderr, "storage.encryption_key (ENCRYPTION_KEY) is not set; generate one with `keys generate`")
+		return 1
+	}
+	if err := crypt.SetKeys(cfg.Storage.EncryptionKey, cfg.Storage.OldEncryptionKeys); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid encryption keys: %v\n", err)
+		return 1
+	}
+
+	// Holding the database lock first means a running assistant stops the
+	// rotation before any file is rewritten under it
+	db, err := storage.Open(cfg.Storage.Database)
+	if err != nil {
+		fmt.Printf("❌ %-21s %v\n", "storage.database", err)
+		fmt.Fprintln(os.Stderr, "\nNothing was re-encrypted.")
+		return 1
+	}
+	defer db.Close()
+
+	files := cfg.Storage.Files()
+	keys := make([]string, 0, len(files))
+	for key := range files {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	code := 0
+	for _, key := range keys {
+		path := files[key]
+		was, err := crypt.Rewrite(path)
+		switch {
+		case os.IsNotExist(err):
+			fmt.Printf("  %-22s %s: not created yet\n", key, path)
+		case err != nil:
+			fmt.Printf("❌ %-21s %v\n", key, err)
+			code = 1
+		default:
+			fmt.Printf("✅ %-21s %s: re-encrypted, was %s\n", key, path, was)
+		}
+	}
+
+	count, err := db.Reencrypt()
+	if err != nil {
+		fmt.Printf("❌ %-21s %v\n", "storage.database", err)
+		code = 1
+	} else {
+		fmt.Printf("✅ %-21s %s: re-encrypted %d values\n", "storage.database", cfg.Storage.Database, count)
+	}
+
+	if code != 0 {
+		fmt.Fprintln(os.Stderr, "\nSome files were not re-encrypted; keep the old keys until they are.")
+		return code
+	}
+	fmt.Fprintf(os.Stderr, "\nAll files use key %s; storage.old_encryption_keys can be removed.\n", crypt.CurrentKeyID())
+	return 0
+}
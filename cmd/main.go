@@ -1,86 +1,315 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
-	"os/signal"
-	"syscall"
+	"strings"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"virtual-assistant/internal/api"
 	"virtual-assistant/internal/bot"
 	"virtual-assistant/internal/calendar"
 	"virtual-assistant/internal/config"
+	"virtual-assistant/internal/contacts"
+	"virtual-assistant/internal/crypt"
+	"virtual-assistant/internal/health"
+	"virtual-assistant/internal/lifecycle"
 	"virtual-assistant/internal/llm"
+	"virtual-assistant/internal/logging"
 	"virtual-assistant/internal/reminder"
+	"virtual-assistant/internal/rsvp"
+	"virtual-assistant/internal/settings"
+	"virtual-assistant/internal/storage"
+	"virtual-assistant/internal/timezone"
 )
 
 func main() {
-	cfg := config.Load()
+	configPath := flag.String("config", "", "config file (default $CONFIG_FILE, or config.yaml if it exists)")
+	profile := flag.String("profile", "", "profile from the config file to apply (default $PROFILE)")
+	flag.Usage = usage
+	flag.Parse()
 
-	if cfg.TelegramBotToken == "" {
-		log.Fatal("TELEGRAM_BOT_TOKEN is required")
+	cfg, err := config.Load(*configPath, *profile)
+	if err != nil {
+		fatal("failed to load config", err)
+	}
+
+	args := flag.Args()
+	command := ""
+	if len(args) > 0 {
+		command = args[0]
+	}
+	switch {
+	case command == "" || command == "serve" && len(args) == 1:
+		serve(cfg)
+	case command == "config" && len(args) == 2 && args[1] == "check":
+		os.Exit(checkConfig(cfg))
+	case command == "keys" && len(args) == 2 && args[1] == "generate":
+		os.Exit(generateKey())
+	case command == "keys" && len(args) == 2 && args[1] == "rotate":
+		os.Exit(rotateKeys(cfg))
+	case command == "auth" && len(args) == 1:
+		os.Exit(authorize(cfg))
+	case command == "agenda":
+		os.Exit(printAgenda(cfg, strings.Join(args[1:], " ")))
+	case command == "send-test-reminder" && len(args) == 2:
+		os.Exit(sendTestReminder(cfg, args[1]))
+	case command == "doctor" && len(args) == 1:
+		os.Exit(doctor(cfg))
+	case command == "webhook" && len(args) == 2:
+		os.Exit(manageWebhook(cfg, args[1]))
+	default:
+		usage()
+		os.Exit(2)
+	}
+}
+
+// serve runs the assistant until it gets a signal to stop.
+func serve(cfg *config.Config) {
+	if err := setup(cfg); err != nil {
+		fatal("failed to apply config", err)
+	}
+	if err := validate(cfg); err != nil {
+		fatal("invalid config", err)
 	}
+	slog.Info("config loaded", "file", cfg.File, "profile", cfg.Profile, "timezone", cfg.Timezone, "encrypted_storage", crypt.CurrentKeyID() != "")
 
-	if cfg.ClaudeCodePath == "" {
-		log.Fatal("CLAUDE_CODE_PATH is required (path to claude executable)")
+	calendarService, err := calendar.NewCalendarService(cfg.Storage.GoogleCredentials, cfg.Storage.GoogleToken, cfg.Server.OAuthPort)
+	if err != nil {
+		fatal("failed to create calendar service", err)
 	}
 
-	calendarService, err := calendar.NewCalendarService(cfg.GoogleCredentialsPath)
+	claudeService, err := llm.NewClaudeCodeService(cfg.LLM.Path)
 	if err != nil {
-		log.Fatalf("Failed to create calendar service: %v", err)
+		fatal("failed to create Claude Code service", err)
+	}
+	claudeService.SetLimits(cfg.LLM.Concurrency, cfg.LLM.QueueSize)
+	claudeService.SetTimeout(cfg.LLM.Timeout)
+	if err := claudeService.SetInstructions(cfg.LLM.Instructions); err != nil {
+		fatal("failed to set prompt instructions", err)
 	}
 
-	claudeService, err := llm.NewClaudeCodeService(cfg.ClaudeCodePath)
+	db, err := storage.Open(cfg.Storage.Database)
 	if err != nil {
-		log.Fatalf("Failed to create Claude Code service: %v", err)
+		fatal("failed to open database", err)
+	}
+	db.SetHistoryLimit(cfg.Storage.HistoryLimit)
+	if _, err := db.ImportChatIDs(cfg.Storage.ChatIDs); err != nil {
+		fatal("failed to import chat IDs", err)
+	}
+	if _, err := db.ImportSettings(cfg.Storage.Settings); err != nil {
+		fatal("failed to import user settings", err)
+	}
+	if _, err := db.ImportContacts(cfg.Storage.Contacts); err != nil {
+		fatal("failed to import contacts", err)
+	}
+	if _, err := db.ImportRSVPState(cfg.Storage.RSVPState); err != nil {
+		fatal("failed to import RSVP state", err)
 	}
 
-	telegramBot, err := bot.NewTelegramBot(cfg.TelegramBotToken, cfg.WebhookURL, calendarService, claudeService)
+	settingsStore, err := settings.NewStore(db)
 	if err != nil {
-		log.Fatalf("Failed to create Telegram bot: %v", err)
+		fatal("failed to load user settings", err)
 	}
 
-	reminderService := reminder.NewReminderService(calendarService, telegramBot)
+	contactBook, err := contacts.NewBook(db)
+	if err != nil {
+		fatal("failed to load contacts", err)
+	}
+
+	telegramBot, err := bot.NewTelegramBot(cfg.Telegram.Token, cfg.Telegram.WebhookURL, calendarService, claudeService, settingsStore, contactBook, db)
+	if err != nil {
+		fatal("failed to create Telegram bot", err)
+	}
+	telegramBot.SetMeetByDefault(cfg.Events.MeetByDefault)
+	telegramBot.SetConcurrency(cfg.Telegram.Workers, cfg.Telegram.QueueSize, cfg.Telegram.UpdateTimeout)
+	telegramBot.SetAllowedChats(cfg.Access.AllowedChats)
+	telegramBot.SetOwners(cfg.Access.Owners)
+
+	// Pick up names of people we've met with so they can be invited by name
+	go func() {
+		if _, err := telegramBot.LearnContactsFromCalendar(); err != nil {
+			slog.Warn("failed to learn contacts", "error", err)
+		}
+	}()
+
+	reminderService := reminder.NewReminderService(calendarService, telegramBot, db)
+	reminderService.SetAllDayNotice(cfg.Reminders.AllDayNotice, cfg.Reminders.AllDayNoticeHour)
+	reminderService.SetTiers(cfg.Reminders.Tiers)
+	reminderService.SetQuietHours(cfg.Reminders.QuietFrom, cfg.Reminders.QuietUntil)
+	if cfg.Telegram.ChatID != 0 {
+		reminderService.SetUserChatID(cfg.Telegram.ChatID)
+	}
+	telegramBot.SetReminders(reminderService)
+
+	rsvpService := rsvp.NewRSVPService(calendarService, telegramBot, db)
+
+	lifecycleManager := lifecycle.New()
+
+	// Settings that are safe to change take effect on SIGHUP or when the
+	// config file is saved
+	watcher := config.NewWatcher(cfg)
+	watcher.Subscribe(func(cfg *config.Config) {
+		if err := setupLogging(cfg); err != nil {
+			slog.Error("failed to apply logging settings", "error", err)
+		}
+		telegramBot.SetAllowedChats(cfg.Access.AllowedChats)
+		telegramBot.SetOwners(cfg.Access.Owners)
+		telegramBot.SetMeetByDefault(cfg.Events.MeetByDefault)
+		claudeService.SetTimeout(cfg.LLM.Timeout)
+		if err := claudeService.SetInstructions(cfg.LLM.Instructions); err != nil {
+			slog.Error("keeping the previous prompt instructions", "error", err)
+		}
+		reminderService.SetTiers(cfg.Reminders.Tiers)
+		reminderService.SetAllDayNotice(cfg.Reminders.AllDayNotice, cfg.Reminders.AllDayNoticeHour)
+		reminderService.SetQuietHours(cfg.Reminders.QuietFrom, cfg.Reminders.QuietUntil)
+		db.SetHistoryLimit(cfg.Storage.HistoryLimit)
+	})
+	watchCtx, stopWatching := context.WithCancel(context.Background())
+	go watcher.Run(watchCtx)
+	lifecycleManager.OnShutdown("stop watching config", func(ctx context.Context) error {
+		stopWatching()
+		return nil
+	})
 
-	if cfg.WebhookURL != "" {
-		log.Println("Starting webhook mode...")
+	// External APIs are probed at most once per interval however often
+	// /readyz is polled
+	checker := health.New()
+	checker.Add("calendar", health.Cached(time.Minute, calendarService.Health))
+	checker.Add("llm", health.Cached(5*time.Minute, claudeService.Health))
+	checker.Add("telegram", health.Cached(30*time.Second, telegramBot.Health))
+	checker.Add("reminders", reminderService.Health)
+	checker.Add("storage", db.Health)
+	lifecycleManager.OnShutdown("mark not ready", func(ctx context.Context) error {
+		checker.SetDraining()
+		return nil
+	})
+
+	http.Handle("/metrics", promhttp.Handler())
+	http.HandleFunc("/livez", checker.LiveHandler)
+	http.HandleFunc("/readyz", checker.ReadyHandler)
+	http.HandleFunc("/health", checker.LiveHandler)
+	if cfg.Server.APIToken != "" {
+		apiServer := api.New(cfg.Server.APIToken, telegramBot, calendarService, reminderService)
+		apiServer.SetTimeout(cfg.Telegram.UpdateTimeout)
+		http.Handle("/api/", apiServer.Handler())
+		slog.Info("REST API enabled", "path", "/api/v1")
+	}
+	server := &http.Server{Addr: fmt.Sprintf(":%d", cfg.Server.Port)}
+
+	if cfg.Telegram.WebhookURL != "" {
+		slog.Info("starting webhook mode")
 		
+		err = telegramBot.SetWebhookSecurity(cfg.Telegram.WebhookSecret, cfg.Telegram.WebhookAllowedIPs, cfg.Telegram.WebhookTrustProxy)
+		if err != nil {
+			fatal("invalid webhook settings", err)
+		}
+
 		err = telegramBot.SetWebhook()
 		if err != nil {
-			log.Fatalf("Failed to set webhook: %v", err)
+			fatal("failed to set webhook", err)
 		}
 
 		http.HandleFunc("/webhook", telegramBot.HandleWebhook)
-		http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-			w.WriteHeader(http.StatusOK)
-			fmt.Fprint(w, "OK")
-		})
 
-		reminderService.Start()
+		if err := reminderService.Start(); err != nil {
+			fatal("failed to start reminders", err)
+		}
+		rsvpService.Start()
 
-		log.Printf("Server starting on port %s", cfg.Port)
-		log.Printf("Webhook URL: %s/webhook", cfg.WebhookURL)
-		
-		go func() {
-			if err := http.ListenAndServe(":"+cfg.Port, nil); err != nil {
-				log.Fatalf("Server failed: %v", err)
-			}
-		}()
+		slog.Info("webhook registered", "url", cfg.Telegram.WebhookURL+"/webhook")
+
+		lifecycleManager.OnShutdown("stop accepting updates", server.Shutdown)
 	} else {
-		log.Println("Starting polling mode...")
+		slog.Info("starting polling mode")
 		
-		reminderService.Start()
+		if err := reminderService.Start(); err != nil {
+			fatal("failed to start reminders", err)
+		}
+		rsvpService.Start()
 		
 		go telegramBot.StartPolling()
+
+		lifecycleManager.OnShutdown("stop accepting updates", func(ctx context.Context) error {
+			telegramBot.StopPolling()
+			return nil
+		})
+		// The REST API and health endpoints go too, before the database closes
+		lifecycleManager.OnShutdown("stop HTTP server", server.Shutdown)
 	}
 
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	// Serves the webhook, if any, plus the health endpoints and /metrics in both modes
+	slog.Info("HTTP server starting", "port", cfg.Server.Port)
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			fatal("HTTP server failed", err)
+		}
+	}()
+
+	lifecycleManager.OnShutdown("drain in-flight updates", func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, cfg.Shutdown.DrainTimeout)
+		defer cancel()
+		return telegramBot.Drain(ctx)
+	})
+	lifecycleManager.OnShutdown("stop reminders", reminderService.Stop)
+	lifecycleManager.OnShutdown("stop RSVP tracker", rsvpService.Stop)
+	lifecycleManager.OnShutdown("flush stores", func(ctx context.Context) error {
+		return errors.Join(settingsStore.Flush(), contactBook.Flush())
+	})
+	lifecycleManager.OnShutdown("close database", func(ctx context.Context) error {
+		return db.Close()
+	})
+	if cfg.Telegram.WebhookURL != "" && cfg.Telegram.DeleteWebhookOnShutdown {
+		lifecycleManager.OnShutdown("delete webhook", func(ctx context.Context) error {
+			return telegramBot.DeleteWebhook()
+		})
+	}
 
-	log.Println("Virtual Assistant is running. Press Ctrl+C to exit.")
-	<-c
+	slog.Info("virtual assistant is running, press Ctrl+C to exit")
+	sig := lifecycleManager.WaitForSignal()
 
-	log.Println("Shutting down...")
-	reminderService.Stop()
+	slog.Info("shutting down", "signal", sig.String())
+	if err := lifecycleManager.Shutdown(cfg.Shutdown.Timeout); err != nil {
+		slog.Error("shutdown finished with errors", "error", err)
+		os.Exit(1)
+	}
+	slog.Info("shutdown complete")
+}
+
+// setup applies the logging, timezone and encryption settings every command
+// that goes past reading the config needs.
+func setup(cfg *config.Config) error {
+	if err := setupLogging(cfg); err != nil {
+		return fmt.Errorf("invalid logging settings: %v", err)
+	}
+	timezone.Set(cfg.Timezone)
+	if err := crypt.SetKeys(cfg.Storage.EncryptionKey, cfg.Storage.OldEncryptionKeys); err != nil {
+		return fmt.Errorf("invalid encryption keys: %v", err)
+	}
+	return nil
+}
+
+func setupLogging(cfg *config.Config) error {
+	return logging.Setup(logging.Options{
+		Level:         cfg.Logging.Level,
+		PackageLevels: cfg.Logging.Levels,
+		Format:        cfg.Logging.Format,
+		Redact:        cfg.Logging.Redact,
+	})
+}
+
+// fatal logs msg, and err if there is one, then exits.
+func fatal(msg string, err error) {
+	if err != nil {
+		slog.Error(msg, "error", err)
+	} else {
+		slog.Error(msg)
+	}
+	os.Exit(1)
 }
\ No newline at end of file
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"virtual-assistant/internal/bot"
+	"virtual-assistant/internal/config"
+	"virtual-assistant/internal/reminder"
+	"virtual-assistant/internal/settings"
+	"virtual-assistant/internal/storage"
+)
+
+// manageWebhook registers, removes or describes the Telegram webhook. It
+// returns the exit code.
+func manageWebhook(cfg *config.Config, action string) int {
+	if action != "set" && action != "delete" && action != "info" {
+		usage()
+		return 2
+	}
+	if err := setup(cfg); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	telegramBot, err := bot.NewTelegramBot(cfg.Telegram.Token, cfg.Telegram.WebhookURL, nil, nil, nil, nil, nil)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	switch action {
+	case "set":
+		// Without a fixed secret the assistant picks a new one on every start,
+		// which this registration wouldn't match
+		if cfg.Telegram.WebhookURL == "" || cfg.Telegram.WebhookSecret == "" {
+			fmt.Fprintln(os.Stderr, "telegram.webhook_url (WEBHOOK_URL) and telegram.webhook_secret (WEBHOOK_SECRET) must be set; otherwise the assistant registers the webhook itself on start")
+			return 1
+		}
+		err := telegramBot.SetWebhookSecurity(cfg.Telegram.WebhookSecret, cfg.Telegram.WebhookAllowedIPs, cfg.Telegram.WebhookTrustProxy)
+		if err == nil {
+			err = telegramBot.SetWebhook()
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to set webhook: %v\n", err)
+			return 1
+		}
+		fmt.Printf("✅ Webhook set to %s/webhook\n", cfg.Telegram.WebhookURL)
+
+	case "delete":
+		if err := telegramBot.DeleteWebhook(); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to delete webhook: %v\n", err)
+			return 1
+		}
+		fmt.Println("✅ Webhook deleted; Telegram holds updates until it's set again or the assistant polls")
+
+	case "info":
+		info, err := telegramBot.WebhookInfo()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to get webhook info: %v\n", err)
+			return 1
+		}
+		if info.URL == "" {
+			fmt.Printf("@%s has no webhook; updates are fetched by polling\n", telegramBot.Username())
+			fmt.Printf("Pending updates:  %d\n", info.PendingUpdateCount)
+			return 0
+		}
+		fmt.Printf("URL:              %s\n", info.URL)
+		fmt.Printf("Pending updates:  %d\n", info.PendingUpdateCount)
+		if info.IPAddress != "" {
+			fmt.Printf("IP address:       %s\n", info.IPAddress)
+		}
+		if info.LastErrorDate != 0 {
+			at := time.Unix(int64(info.LastErrorDate), 0)
+			fmt.Printf("Last error:       %s (%s ago): %s\n", at.Format(time.RFC3339), time.Since(at).Round(time.Second), info.LastErrorMessage)
+		}
+		if cfg.Telegram.WebhookURL != "" && info.URL != cfg.Telegram.WebhookURL+"/webhook" {
+			fmt.Printf("⚠️ The config expects %s/webhook\n", cfg.Telegram.WebhookURL)
+		}
+	}
+	return 0
+}
+
+// sendTestReminder sends a chat the reminder for a made-up meeting, in the
+// chat's language when the database is free to read it. It returns the exit
+// code.
+func sendTestReminder(cfg *config.Config, chat string) int {
+	chatID, err := strconv.ParseInt(chat, 10, 64)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid chat ID %q\n", chat)
+		return 2
+	}
+	if err := setup(cfg); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	// A running assistant holds the database, so settle for the default
+	// language rather than fail
+	var settingsStore *settings.Store
+	db, err := storage.Open(cfg.Storage.Database)
+	if err == nil {
+		defer db.Close()
+		settingsStore, err = settings.NewStore(db)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️ Can't read the chat's settings, sending in the default language: %v\n", err)
+	}
+
+	telegramBot, err := bot.NewTelegramBot(cfg.Telegram.Token, "", nil, nil, settingsStore, nil, db)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	reminderService := reminder.NewReminderService(nil, telegramBot, db)
+	reminderService.SetTiers(cfg.Reminders.Tiers)
+	if err := reminderService.SendTestReminder(chatID); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Failed to send the reminder: %v\n", err)
+		return 1
+	}
+	fmt.Printf("✅ Sent a test reminder to chat %d\n", chatID)
+	return 0
+}
@@ -0,0 +1,361 @@
+// Package api serves the REST API under /api/v1, so scripts and internal
+// tools can reach the assistant without Telegram. Every request needs the
+// configured token as "Authorization: Bearer <token>"; bodies and responses
+// are JSON.
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	gcal "google.golang.org/api/calendar/v3"
+	"virtual-assistant/internal/bot"
+	"virtual-assistant/internal/calendar"
+	"virtual-assistant/internal/logging"
+	"virtual-assistant/internal/markup"
+	"virtual-assistant/internal/timezone"
+)
+
+// Larger request bodies are rejected
+const maxBodySize = 1 << 20
+
+// DefaultTimeout bounds a request, which may wait for Claude.
+const DefaultTimeout = 2 * time.Minute
+
+var logger = logging.For("api")
+
+// Server handles the API requests.
+type Server struct {
+	token           string
+	telegramBot     *bot.TelegramBot
+	calendarService *calendar.CalendarService
+	reminders       bot.ReminderLister
+	timeout         time.Duration
+}
+
+func New(token string, telegramBot *bot.TelegramBot, calendarService *calendar.CalendarService, reminders bot.ReminderLister) *Server {
+	return &Server{
+		token:           token,
+		telegramBot:     telegramBot,
+		calendarService: calendarService,
+		reminders:       reminders,
+		timeout:         DefaultTimeout,
+	}
+}
+
+// SetTimeout sets how long a request may take. Call it before serving.
+func (s *Server) SetTimeout(timeout time.Duration) {
+	if timeout > 0 {
+		s.timeout = timeout
+	}
+}
+
+// Handler returns the API's routes, to be mounted at /api/.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/messages", s.route("messages", http.MethodPost, s.postMessage))
+	mux.HandleFunc("/api/v1/agenda", s.route("agenda", http.MethodGet, s.getAgenda))
+	mux.HandleFunc("/api/v1/events", s.route("events", http.MethodPost, s.postEvent))
+	mux.HandleFunc("/api/v1/reminders", s.route("reminders", http.MethodGet, s.getReminders))
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, "no such endpoint")
+	})
+	return mux
+}
+
+// handlerFunc handles an authenticated request and returns the status and
+// value to write as JSON. Errors become {"error": "..."}.
+type handlerFunc func(ctx context.Context, r *http.Request) (int, interface{}, error)
+
+// route checks the method and token, then runs handle with the request
+// timeout and records the outcome.
+func (s *Server) route(endpoint, method string, handle handlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ctx := logging.WithRequestID(r.Context(), logging.NewRequestID())
+		r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+
+		status, value, err := s.serve(ctx, endpoint, method, handle, r)
+		if err != nil {
+			if status >= http.StatusInternalServerError {
+				logger.ErrorContext(ctx, "API request failed", "endpoint", endpoint, "error", err)
+			}
+			value = errorBody{Error: err.Error()}
+		}
+		if status == http.StatusMethodNotAllowed {
+			w.Header().Set("Allow", method)
+		}
+		writeJSON(w, status, value)
+
+		requestsTotal.WithLabelValues(endpoint, strconv.Itoa(status)).Inc()
+		requestDuration.WithLabelValues(endpoint).Observe(time.Since(start).Seconds())
+		logger.InfoContext(ctx, "API request", "endpoint", endpoint, "status", status, "duration", time.Since(start).Round(time.Millisecond).String())
+	}
+}
+
+func (s *Server) serve(ctx context.Context, endpoint, method string, handle handlerFunc, r *http.Request) (int, interface{}, error) {
+	if !s.authorized(r) {
+		return http.StatusUnauthorized, nil, errors.New("missing or invalid bearer token")
+	}
+	if r.Method != method {
+		return http.StatusMethodNotAllowed, nil, fmt.Errorf("%s only accepts %s", endpoint, method)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return handle(ctx, r)
+}
+
+// authorized compares the bearer token in constant time.
+func (s *Server) authorized(r *http.Request) bool {
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return found && subtle.ConstantTimeCompare([]byte(strings.TrimSpace(token)), []byte(s.token)) == 1
+}
+
+type messageRequest struct {
+	ChatID int64  `json:"chat_id"` // Whose settings and pending confirmations apply
+	Text   string `json:"text"`
+}
+
+type messageResponse struct {
+	Reply string `json:"reply"` // Plain text
+	HTML  string `json:"html"`  // As Telegram would show it
+}
+
+// postMessage answers a message like the bot would in chat chat_id.
+func (s *Server) postMessage(ctx context.Context, r *http.Request) (int, interface{}, error) {
+	var req messageRequest
+	if err := decode(r, &req); err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+	if strings.TrimSpace(req.Text) == "" {
+		return http.StatusBadRequest, nil, errors.New("text is required")
+	}
+	if req.ChatID == 0 {
+		return http.StatusBadRequest, nil, errors.New("chat_id is required")
+	}
+
+	reply, err := s.telegramBot.Ask(ctx, req.ChatID, req.Text)
+	if errors.Is(err, bot.ErrQueueFull) || errors.Is(err, bot.ErrStopped) {
+		return http.StatusServiceUnavailable, nil, err
+	}
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+	return http.StatusOK, messageResponse{Reply: markup.PlainText(reply), HTML: string(reply)}, nil
+}
+
+// Event is a calendar event as the API shows it. Timed events start and end
+// at RFC 3339 times; all-day events at dates, with end the last day.
+type Event struct {
+	ID          string   `json:"id,omitempty"`
+	Title       string   `json:"title"`
+	Description string   `json:"description,omitempty"`
+	Location    string   `json:"location,omitempty"`
+	Start       string   `json:"start"`
+	End         string   `json:"end"`
+	AllDay      bool     `json:"all_day,omitempty"`
+	Attendees   []string `json:"attendees,omitempty"`
+	Recurrence  string   `json:"recurrence,omitempty"` // RRULE, e.g. FREQ=WEEKLY;BYDAY=TU
+	CalendarID  string   `json:"calendar_id,omitempty"`
+	Meet        bool     `json:"meet,omitempty"`     // When creating: attach a Google Meet link
+	JoinURL     string   `json:"join_url,omitempty"` // Meet or other conference link
+	Link        string   `json:"link,omitempty"`     // The event in Google Calendar
+}
+
+type agendaResponse struct {
+	Date     string  `json:"date"`
+	Timezone string  `json:"timezone"`
+	Events   []Event `json:"events"`
+}
+
+// getAgenda lists the events of ?date=YYYY-MM-DD, today by default, from the
+// calendars chosen in ?chat_id's settings, or the primary calendar.
+func (s *Server) getAgenda(ctx context.Context, r *http.Request) (int, interface{}, error) {
+	loc := timezone.Location()
+	day := time.Now().In(loc)
+	if date := r.URL.Query().Get("date"); date != "" {
+		var err error
+		if day, err = time.ParseInLocation("2006-01-02", date, loc); err != nil {
+			return http.StatusBadRequest, nil, fmt.Errorf("date must be YYYY-MM-DD, got %q", date)
+		}
+	}
+	var calendarIDs []string
+	if chat := r.URL.Query().Get("chat_id"); chat != "" {
+		chatID, err := strconv.ParseInt(chat, 10, 64)
+		if err != nil {
+			return http.StatusBadRequest, nil, fmt.Errorf("invalid chat_id %q", chat)
+		}
+		calendarIDs = s.telegramBot.CalendarsFor(chatID)
+	}
+
+	events, err := s.calendarService.GetEventsOn(calendarIDs, day)
+	if err != nil {
+		return http.StatusBadGateway, nil, err
+	}
+	response := agendaResponse{Date: day.Format("2006-01-02"), Timezone: loc.String(), Events: []Event{}}
+	for _, event := range events {
+		response.Events = append(response.Events, toEvent(event))
+	}
+	return http.StatusOK, response, nil
+}
+
+// postEvent creates an event as given, without the bot's conflict check.
+func (s *Server) postEvent(ctx context.Context, r *http.Request) (int, interface{}, error) {
+	var req Event
+	if err := decode(r, &req); err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+	eventRequest, err := req.toRequest()
+	if err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+
+	created, err := s.calendarService.InsertEvent(eventRequest)
+	if err != nil {
+		return http.StatusBadGateway, nil, err
+	}
+	logger.InfoContext(ctx, "created event through the API", "event_id", created.Id, "title", created.Summary)
+	event := toEvent(created)
+	event.CalendarID = req.CalendarID
+	return http.StatusCreated, event, nil
+}
+
+// toRequest checks an event to be created.
+func (e Event) toRequest() (*calendar.EventRequest, error) {
+	if strings.TrimSpace(e.Title) == "" {
+		return nil, errors.New("title is required")
+	}
+	layout, unit := time.RFC3339, "RFC 3339 times"
+	if e.AllDay {
+		layout, unit = "2006-01-02", "YYYY-MM-DD dates"
+	}
+	start, err := time.ParseInLocation(layout, e.Start, timezone.Location())
+	if err != nil {
+		return nil, fmt.Errorf("start and end must be %s, got start %q", unit, e.Start)
+	}
+	end, err := time.ParseInLocation(layout, e.End, timezone.Location())
+	if err != nil {
+		return nil, fmt.Errorf("start and end must be %s, got end %q", unit, e.End)
+	}
+	if end.Before(start) || !e.AllDay && end.Equal(start) {
+		return nil, errors.New("end must be after start")
+	}
+
+	req := &calendar.EventRequest{
+		Title:       e.Title,
+		Description: e.Description,
+		StartTime:   e.Start,
+		EndTime:     e.End,
+		AllDay:      e.AllDay,
+		Attendees:   e.Attendees,
+		CalendarID:  e.CalendarID,
+		Conference:  e.Meet,
+	}
+	for _, email := range e.Attendees {
+		if !strings.Contains(email, "@") {
+			return nil, fmt.Errorf("attendees must be email addresses, got %q", email)
+		}
+	}
+	if e.Recurrence != "" {
+		rule, err := calendar.ParseRecurrence(e.Recurrence)
+		if err != nil {
+			return nil, fmt.Errorf("invalid recurrence: %v", err)
+		}
+		if rule != "" {
+			req.Recurrence = []string{rule}
+		}
+	}
+	return req, nil
+}
+
+func toEvent(event *gcal.Event) Event {
+	e := Event{
+		ID:          event.Id,
+		Title:       event.Summary,
+		Description: event.Description,
+		Location:    event.Location,
+		JoinURL:     calendar.JoinURL(event),
+		Link:        event.HtmlLink,
+	}
+	if len(event.Recurrence) > 0 {
+		e.Recurrence = strings.TrimPrefix(event.Recurrence[0], "RRULE:")
+	}
+	for _, attendee := range event.Attendees {
+		e.Attendees = append(e.Attendees, attendee.Email)
+	}
+
+	if calendar.IsAllDay(event) {
+		e.AllDay = true
+		e.Start = event.Start.Date
+		e.End = event.Start.Date
+		if _, end, err := calendar.AllDayRange(event); err == nil {
+			e.End = end.AddDate(0, 0, -1).Format("2006-01-02")
+		}
+		return e
+	}
+	e.Start = event.Start.DateTime
+	if event.End != nil {
+		e.End = event.End.DateTime
+	}
+	return e
+}
+
+// Reminder is a meeting reminder as the API shows it. Due is when a pending
+// reminder goes out, or when a sent one went out.
+type Reminder struct {
+	Title     string    `json:"title"`
+	EventTime time.Time `json:"event_time"`
+	Due       time.Time `json:"due"`
+	Sent      bool      `json:"sent"`
+}
+
+// getReminders lists the reminders due in the next day and those sent in the
+// last day.
+func (s *Server) getReminders(ctx context.Context, r *http.Request) (int, interface{}, error) {
+	reminders, err := s.reminders.ListReminders(ctx)
+	if err != nil {
+		return http.StatusBadGateway, nil, err
+	}
+	response := struct {
+		Reminders []Reminder `json:"reminders"`
+	}{Reminders: []Reminder{}}
+	for _, reminder := range reminders {
+		response.Reminders = append(response.Reminders, Reminder(reminder))
+	}
+	return http.StatusOK, response, nil
+}
+
+type errorBody struct {
+	Error string `json:"error"`
+}
+
+// decode reads a JSON body into v, refusing unknown fields so typos don't
+// go unnoticed.
+func decode(r *http.Request, v interface{}) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("invalid JSON body: %v", err)
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		logger.Warn("failed to write API response", "error", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, errorBody{Error: message})
+}
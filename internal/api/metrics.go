@@ -0,0 +1,19 @@
+package api
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	requestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "assistant_api_requests_total",
+		Help: "REST API requests, by endpoint and HTTP status.",
+	}, []string{"endpoint", "status"})
+
+	requestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "assistant_api_request_duration_seconds",
+		Help:    "REST API request latency, by endpoint.",
+		Buckets: []float64{0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 120},
+	}, []string{"endpoint"})
+)
@@ -0,0 +1,89 @@
+package bot
+
+import (
+	"context"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"virtual-assistant/internal/i18n"
+	"virtual-assistant/internal/markup"
+	"virtual-assistant/internal/storage"
+)
+
+// SetAllowedChats limits the bot to the given chats. With none, anyone who
+// finds the bot can use it. It can be called while updates are handled.
+func (tb *TelegramBot) SetAllowedChats(chatIDs []int64) {
+	allowed := make(map[int64]bool, len(chatIDs))
+	for _, chatID := range chatIDs {
+		allowed[chatID] = true
+	}
+	tb.allowedChats.Store(&allowed)
+}
+
+func (tb *TelegramBot) allowed(chatID int64) bool {
+	allowed := tb.allowedChats.Load()
+	return allowed == nil || len(*allowed) == 0 || (*allowed)[chatID]
+}
+
+// SetOwners sets the users who own the bot: they can use every admin command
+// and can't be banned. It can be called while updates are handled.
+func (tb *TelegramBot) SetOwners(userIDs []int64) {
+	owners := make(map[int64]bool, len(userIDs))
+	for _, userID := range userIDs {
+		owners[userID] = true
+	}
+	tb.owners.Store(&owners)
+}
+
+// roleOf returns what a user may do: owners come from the config, other
+// roles from the database.
+func (tb *TelegramBot) roleOf(userID int64) storage.Role {
+	if owners := tb.owners.Load(); owners != nil && (*owners)[userID] {
+		return storage.RoleOwner
+	}
+	role, err := tb.db.Role(userID)
+	if err != nil {
+		logger.Error("failed to load role", "user_id", userID, "error", err)
+		return storage.RoleUser
+	}
+	return role
+}
+
+type senderKey struct{}
+
+// withSender notes who sent the update being handled, for the role checks of
+// admin commands.
+func withSender(ctx context.Context, userID int64) context.Context {
+	return context.WithValue(ctx, senderKey{}, userID)
+}
+
+func senderOf(ctx context.Context) int64 {
+	userID, _ := ctx.Value(senderKey{}).(int64)
+	return userID
+}
+
+// updateUserID returns who sent an update, or 0 if it has no sender.
+func updateUserID(update tgbotapi.Update) int64 {
+	switch {
+	case update.Message != nil && update.Message.From != nil:
+		return update.Message.From.ID
+	case update.CallbackQuery != nil && update.CallbackQuery.From != nil:
+		return update.CallbackQuery.From.ID
+	}
+	return 0
+}
+
+// refuse tells a chat that isn't on the allowlist its ID, so the owner can
+// add it if they meant to.
+func (tb *TelegramBot) refuse(ctx context.Context, update tgbotapi.Update, chatID int64) {
+	logger.WarnContext(ctx, "ignoring update from chat not on the allowlist", "update_id", update.UpdateID, "chat_id", chatID)
+	updatesRejected.WithLabelValues("not_allowed").Inc()
+	if update.Message == nil {
+		return
+	}
+
+	lang := i18n.FromTelegram(update.Message.From.LanguageCode)
+	text := markup.Escape(i18n.T(lang, "access.denied", chatID))
+	if _, err := tb.sendHTML(chatID, text, nil); err != nil {
+		logger.ErrorContext(ctx, "failed to send reply", "chat_id", chatID, "error", err)
+	}
+}
@@ -0,0 +1,399 @@
+package bot
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"virtual-assistant/internal/i18n"
+	"virtual-assistant/internal/logging"
+	"virtual-assistant/internal/markup"
+	"virtual-assistant/internal/storage"
+)
+
+// Pause between broadcast messages, well under Telegram's 30 per second
+const broadcastInterval = 50 * time.Millisecond
+
+var startedAt = time.Now()
+
+// adminCommands maps each admin command to the role it needs. Admins manage
+// users and watch the bot; only owners hand out roles or reach everyone.
+var adminCommands = map[string]storage.Role{
+	"/users":     storage.RoleAdmin,
+	"/ban":       storage.RoleAdmin,
+	"/unban":     storage.RoleAdmin,
+	"/stats":     storage.RoleAdmin,
+	"/reminders": storage.RoleAdmin,
+	"/promote":   storage.RoleOwner,
+	"/demote":    storage.RoleOwner,
+	"/broadcast": storage.RoleOwner,
+	"/loglevel":  storage.RoleOwner,
+}
+
+// ReminderStatus is one reminder as listed by /reminders.
+type ReminderStatus struct {
+	Title     string
+	EventTime time.Time
+	Due       time.Time // When it goes out, or went out if Sent
+	Sent      bool
+}
+
+// ReminderLister lists reminders for /reminders. The reminder service
+// implements it; it lives here because that package depends on this one.
+type ReminderLister interface {
+	ListReminders(ctx context.Context) ([]ReminderStatus, error)
+}
+
+// SetReminders makes /reminders list what lister reports.
+func (tb *TelegramBot) SetReminders(lister ReminderLister) {
+	tb.reminders = lister
+}
+
+// commandArgs returns what follows the command, which may span lines.
+func commandArgs(text string) string {
+	if i := strings.IndexFunc(text, unicode.IsSpace); i >= 0 {
+		return strings.TrimSpace(text[i:])
+	}
+	return ""
+}
+
+func (tb *TelegramBot) handleAdminCommand(ctx context.Context, chatID int64, command, args string) (string, error) {
+	lang := tb.LanguageFor(chatID)
+	userID := senderOf(ctx)
+	role := tb.roleOf(userID)
+	if needed := adminCommands[command]; !role.AtLeast(needed) {
+		logger.WarnContext(ctx, "refused admin command", "command", command, "user_id", userID, "role", role)
+		if needed == storage.RoleOwner {
+			return i18n.T(lang, "admin.owner_only", command), nil
+		}
+		return i18n.T(lang, "admin.admin_only", command), nil
+	}
+	logger.InfoContext(ctx, "admin command", "command", command, "user_id", userID)
+
+	switch command {
+	case "/users":
+		return tb.listUsers(lang)
+	case "/ban":
+		return tb.changeRole(ctx, lang, role, args, storage.RoleBanned, "admin.banned")
+	case "/unban":
+		return tb.changeRole(ctx, lang, role, args, storage.RoleUser, "admin.unbanned")
+	case "/promote":
+		return tb.changeRole(ctx, lang, role, args, storage.RoleAdmin, "admin.promoted")
+	case "/demote":
+		return tb.changeRole(ctx, lang, role, args, storage.RoleUser, "admin.demoted")
+	case "/stats":
+		return tb.stats(lang)
+	case "/broadcast":
+		return tb.broadcast(ctx, chatID, lang, args)
+	case "/reminders":
+		return tb.listReminders(ctx, lang)
+	case "/loglevel":
+		return tb.logLevel(lang, args)
+	}
+	return i18n.T(lang, "admin.help"), nil
+}
+
+func (tb *TelegramBot) listUsers(lang i18n.Language) (string, error) {
+	users, err := tb.db.Users()
+	if err != nil {
+		return "", err
+	}
+	roles, err := tb.db.Roles()
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	sb.WriteString(i18n.T(lang, "admin.users_header", len(users)))
+	seen := make(map[int64]bool, len(users))
+	for _, user := range users {
+		seen[user.ID] = true
+		lastSeen := i18n.FormatTime(lang, user.LastSeen, "2 Jan 15:04")
+		sb.WriteString(i18n.T(lang, "admin.user_line", displayName(user), user.ID, tb.roleLabel(lang, user.ID, roles), lastSeen))
+	}
+
+	// Users banned or promoted before they ever wrote to the bot
+	var unseen []int64
+	for userID := range roles {
+		if !seen[userID] {
+			unseen = append(unseen, userID)
+		}
+	}
+	sort.Slice(unseen, func(i, j int) bool { return unseen[i] < unseen[j] })
+	for _, userID := range unseen {
+		sb.WriteString(i18n.T(lang, "admin.user_unseen", userID, tb.roleLabel(lang, userID, roles)))
+	}
+	return sb.String(), nil
+}
+
+// roleLabel is shown after a user's name, e.g. " · admin"; plain users get
+// none.
+func (tb *TelegramBot) roleLabel(lang i18n.Language, userID int64, roles map[int64]storage.Role) string {
+	role, ok := roles[userID]
+	if owners := tb.owners.Load(); owners != nil && (*owners)[userID] {
+		role, ok = storage.RoleOwner, true
+	}
+	if !ok || role == storage.RoleUser {
+		return ""
+	}
+	return " · " + i18n.T(lang, "role."+string(role))
+}
+
+func displayName(user storage.User) string {
+	name := strings.TrimSpace(user.FirstName + " " + user.LastName)
+	if user.Username != "" {
+		name += " @" + user.Username
+	}
+	if name == "" {
+		name = "?"
+	}
+	return name
+}
+
+// changeRole gives the user named in args a new role. Nobody can change the
+// role of someone at or above their own, so admins can't ban each other and
+// owners can't be banned at all.
+func (tb *TelegramBot) changeRole(ctx context.Context, lang i18n.Language, actorRole storage.Role, args string, role storage.Role, doneKey string) (string, error) {
+	if args == "" {
+		return i18n.T(lang, "admin.help"), nil
+	}
+	userID, name, err := tb.findUser(args)
+	if err != nil {
+		return "", err
+	}
+	if userID == 0 {
+		return i18n.T(lang, "admin.no_user", args), nil
+	}
+
+	if current := tb.roleOf(userID); current == storage.RoleOwner {
+		return i18n.T(lang, "admin.is_owner", name), nil
+	} else if !actorRole.Outranks(current) {
+		return i18n.T(lang, "admin.outranked", name), nil
+	}
+
+	if err := tb.db.SetRole(userID, role); err != nil {
+		return "", err
+	}
+	logger.InfoContext(ctx, "changed user role", "user_id", userID, "role", role, "by", senderOf(ctx))
+	return i18n.T(lang, doneKey, name), nil
+}
+
+// findUser resolves a numeric user ID or a @username to an ID and a name to
+// show. Unknown usernames return 0; unknown IDs are accepted, so users can be
+// banned before they write to the bot.
+func (tb *TelegramBot) findUser(target string) (int64, string, error) {
+	users, err := tb.db.Users()
+	if err != nil {
+		return 0, "", err
+	}
+
+	if id, err := strconv.ParseInt(target, 10, 64); err == nil {
+		for _, user := range users {
+			if user.ID == id {
+				return id, displayName(user), nil
+			}
+		}
+		return id, target, nil
+	}
+
+	username := strings.TrimPrefix(target, "@")
+	for _, user := range users {
+		if strings.EqualFold(user.Username, username) {
+			return user.ID, displayName(user), nil
+		}
+	}
+	return 0, "", nil
+}
+
+func (tb *TelegramBot) stats(lang i18n.Language) (string, error) {
+	users, err := tb.db.Users()
+	if err != nil {
+		return "", err
+	}
+	chats, err := tb.db.ChatIDs()
+	if err != nil {
+		return "", err
+	}
+	roles, err := tb.db.Roles()
+	if err != nil {
+		return "", err
+	}
+	var admins, banned int
+	for _, role := range roles {
+		switch role {
+		case storage.RoleAdmin:
+			admins++
+		case storage.RoleBanned:
+			banned++
+		}
+	}
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		return "", err
+	}
+	handled := counterTotals(families, "assistant_updates_handled_total", "result")
+	rejected := counterTotals(families, "assistant_updates_rejected_total", "")
+	llmRequests := counterTotals(families, "assistant_llm_requests_total", "result")
+	fallbacks := counterTotals(families, "assistant_offline_fallbacks_total", "")
+	sent := counterTotals(families, "assistant_reminders_sent_total", "")
+	failed := counterTotals(families, "assistant_reminders_failed_total", "")
+
+	return i18n.T(lang, "admin.stats",
+		i18n.FormatDuration(lang, time.Since(startedAt)),
+		len(users), admins, banned, len(chats),
+		int(handled["ok"]), int(handled["error"]), int(rejected[""]),
+		int(sum(llmRequests)), int(sum(llmRequests)-llmRequests["ok"]), int(llmRequests["busy"]), int(fallbacks[""]),
+		int(sent[""]), int(failed[""]),
+	), nil
+}
+
+// counterTotals sums a counter by the value of label, or all of it under ""
+// when label is empty.
+func counterTotals(families []*dto.MetricFamily, name, label string) map[string]float64 {
+	totals := make(map[string]float64)
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			key := ""
+			for _, pair := range metric.GetLabel() {
+				if label != "" && pair.GetName() == label {
+					key = pair.GetValue()
+				}
+			}
+			totals[key] += metric.GetCounter().GetValue()
+		}
+	}
+	return totals
+}
+
+func sum(totals map[string]float64) float64 {
+	var total float64
+	for _, value := range totals {
+		total += value
+	}
+	return total
+}
+
+// broadcast sends text to every chat that gets reminders, leaving out banned
+// users. It runs in the background, since pacing the messages can take longer
+// than an update may, and tells replyTo how it went once it's done.
+func (tb *TelegramBot) broadcast(ctx context.Context, replyTo int64, lang i18n.Language, text string) (string, error) {
+	if text == "" {
+		return i18n.T(lang, "admin.broadcast_usage"), nil
+	}
+	chatIDs := tb.GetAllChatIDs()
+
+	// Outlives the update's deadline, but not a shutdown
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(tb.dispatcher.base, cancel)
+	go func() {
+		defer cancel()
+		defer stop()
+
+		sent := 0
+		for _, chatID := range chatIDs {
+			if _, err := tb.sendHTML(chatID, markup.Escape("📢 "+text), nil); err != nil {
+				logger.WarnContext(ctx, "failed to send broadcast", "chat_id", chatID, "error", err)
+			} else {
+				sent++
+			}
+
+			select {
+			case <-time.After(broadcastInterval):
+			case <-ctx.Done():
+			}
+			if ctx.Err() != nil {
+				logger.WarnContext(ctx, "broadcast interrupted by shutdown", "sent", sent, "chats", len(chatIDs))
+				return
+			}
+		}
+		logger.InfoContext(ctx, "sent broadcast", "sent", sent, "chats", len(chatIDs))
+		tb.sendHTML(replyTo, markup.Escape(i18n.T(lang, "admin.broadcast_done", sent, len(chatIDs))), nil)
+	}()
+
+	return i18n.T(lang, "admin.broadcast_started", len(chatIDs)), nil
+}
+
+func (tb *TelegramBot) listReminders(ctx context.Context, lang i18n.Language) (string, error) {
+	if tb.reminders == nil {
+		return i18n.T(lang, "admin.reminders_off"), nil
+	}
+	reminders, err := tb.reminders.ListReminders(ctx)
+	if err != nil {
+		return "", err
+	}
+	if len(reminders) == 0 {
+		return i18n.T(lang, "admin.reminders_none"), nil
+	}
+
+	var upcoming, sent strings.Builder
+	for _, reminder := range reminders {
+		line := i18n.T(lang, "admin.reminder_line",
+			i18n.FormatTime(lang, reminder.Due, "Mon 15:04"), reminder.Title, i18n.FormatTime(lang, reminder.EventTime, "Mon 15:04"))
+		if reminder.Sent {
+			sent.WriteString(line)
+		} else {
+			upcoming.WriteString(line)
+		}
+	}
+
+	var sb strings.Builder
+	if upcoming.Len() > 0 {
+		sb.WriteString(i18n.T(lang, "admin.reminders_upcoming") + upcoming.String())
+	}
+	if sent.Len() > 0 {
+		if sb.Len() > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(i18n.T(lang, "admin.reminders_sent") + sent.String())
+	}
+	return sb.String(), nil
+}
+
+// logLevel shows the log levels, or changes them with "debug" or
+// "package=debug". Changes last until the next config reload.
+func (tb *TelegramBot) logLevel(lang i18n.Language, args string) (string, error) {
+	if args == "" {
+		level, levels := logging.Levels()
+		var overrides []string
+		for pkg, pkgLevel := range levels {
+			overrides = append(overrides, pkg+"="+levelName(pkgLevel))
+		}
+		sort.Strings(overrides)
+		list := ""
+		if len(overrides) > 0 {
+			list = " (" + strings.Join(overrides, ", ") + ")"
+		}
+		return i18n.T(lang, "admin.loglevel", levelName(level), list), nil
+	}
+
+	var changed []string
+	for _, entry := range strings.Fields(strings.ReplaceAll(args, ",", " ")) {
+		pkg, value, found := strings.Cut(entry, "=")
+		if !found {
+			pkg, value = "", entry
+		}
+		if err := logging.SetLevel(pkg, value); err != nil {
+			return i18n.T(lang, "admin.loglevel_bad", value), nil
+		}
+		if pkg == "" {
+			pkg = "*"
+		}
+		changed = append(changed, pkg+"="+strings.ToLower(value))
+	}
+	logger.Info("changed log levels", "levels", changed)
+	return i18n.T(lang, "admin.loglevel_set", strings.Join(changed, ", ")), nil
+}
+
+func levelName(level slog.Level) string {
+	return strings.ToLower(level.String())
+}
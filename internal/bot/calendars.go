@@ -0,0 +1,187 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	gcal "google.golang.org/api/calendar/v3"
+	"virtual-assistant/internal/i18n"
+	"virtual-assistant/internal/settings"
+)
+
+// CalendarsFor returns the calendars the chat reads agendas and reminders from.
+func (tb *TelegramBot) CalendarsFor(chatID int64) []string {
+	userSettings := tb.settings.Get(chatID)
+	return userSettings.ActiveCalendars()
+}
+
+func (tb *TelegramBot) handleCalendarsCommand(chatID int64, args string) (string, error) {
+	entries, err := tb.calendarService.ListCalendars()
+	if err != nil {
+		return "", err
+	}
+
+	lang := tb.LanguageFor(chatID)
+	calendarsHelp := i18n.T(lang, "calendars.help")
+
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		return formatCalendarList(lang, entries, tb.settings.Get(chatID)), nil
+	}
+
+	switch strings.ToLower(fields[0]) {
+	case "use":
+		if len(fields) < 2 {
+			return calendarsHelp, nil
+		}
+		var selected []string
+		for _, ref := range strings.Split(strings.Join(fields[1:], " "), ",") {
+			entry := findCalendar(entries, ref)
+			if entry == nil {
+				return i18n.T(lang, "calendars.not_found_named", strings.TrimSpace(ref)), nil
+			}
+			selected = append(selected, entry.Id)
+		}
+		err = tb.settings.Update(chatID, func(us *settings.UserSettings) {
+			us.Calendars = selected
+		})
+		if err != nil {
+			return "", err
+		}
+		return i18n.T(lang, "calendars.using", len(selected)), nil
+
+	case "default":
+		if len(fields) < 2 {
+			return calendarsHelp, nil
+		}
+		entry := findCalendar(entries, strings.Join(fields[1:], " "))
+		if entry == nil {
+			return i18n.T(lang, "calendars.not_found"), nil
+		}
+		err = tb.settings.Update(chatID, func(us *settings.UserSettings) {
+			us.DefaultCalendar = entry.Id
+		})
+		if err != nil {
+			return "", err
+		}
+		return i18n.T(lang, "calendars.default_set", entry.Summary), nil
+
+	case "rule":
+		if len(fields) < 3 {
+			return calendarsHelp, nil
+		}
+		keyword := strings.ToLower(fields[1])
+		entry := findCalendar(entries, strings.Join(fields[2:], " "))
+		if entry == nil {
+			return i18n.T(lang, "calendars.not_found"), nil
+		}
+		err = tb.settings.Update(chatID, func(us *settings.UserSettings) {
+			rules := []settings.CalendarRule{{Keyword: keyword, CalendarID: entry.Id}}
+			for _, rule := range us.CalendarRules {
+				if rule.Keyword != keyword {
+					rules = append(rules, rule)
+				}
+			}
+			us.CalendarRules = rules
+		})
+		if err != nil {
+			return "", err
+		}
+		return i18n.T(lang, "calendars.rule_set", keyword, entry.Summary), nil
+
+	case "rules":
+		if len(fields) == 2 && strings.EqualFold(fields[1], "clear") {
+			err = tb.settings.Update(chatID, func(us *settings.UserSettings) {
+				us.CalendarRules = nil
+			})
+			if err != nil {
+				return "", err
+			}
+			return i18n.T(lang, "calendars.rules_cleared"), nil
+		}
+	}
+
+	return calendarsHelp, nil
+}
+
+// resolveTargetCalendar picks the calendar for a new event. A calendar the
+// user named explicitly wins, then their keyword rules and default.
+func (tb *TelegramBot) resolveTargetCalendar(chatID int64, calendarName, title, description string) (string, error) {
+	userSettings := tb.settings.Get(chatID)
+
+	if calendarName != "" && !strings.EqualFold(calendarName, "empty") {
+		entries, err := tb.calendarService.ListCalendars()
+		if err != nil {
+			return "", err
+		}
+		if entry := findCalendar(entries, calendarName); entry != nil {
+			return entry.Id, nil
+		}
+	}
+
+	return userSettings.TargetCalendar(title, description), nil
+}
+
+// findCalendar matches a 1-based list position, an ID or a case-insensitive
+// calendar name.
+func findCalendar(entries []*gcal.CalendarListEntry, ref string) *gcal.CalendarListEntry {
+	ref = strings.TrimSpace(ref)
+	if n, err := strconv.Atoi(ref); err == nil {
+		if n >= 1 && n <= len(entries) {
+			return entries[n-1]
+		}
+		return nil
+	}
+	for _, entry := range entries {
+		if entry.Id == ref || strings.EqualFold(entry.Summary, ref) || strings.EqualFold(entry.SummaryOverride, ref) {
+			return entry
+		}
+	}
+	return nil
+}
+
+func formatCalendarList(lang i18n.Language, entries []*gcal.CalendarListEntry, userSettings settings.UserSettings) string {
+	active := make(map[string]bool)
+	for _, id := range userSettings.Calendars {
+		active[id] = true
+	}
+
+	var sb strings.Builder
+	sb.WriteString(i18n.T(lang, "calendars.header"))
+	for i, entry := range entries {
+		included := active[entry.Id] || (len(userSettings.Calendars) == 0 && entry.Primary)
+		marker := "⬜"
+		if included {
+			marker = "✅"
+		}
+		name := entry.Summary
+		if entry.SummaryOverride != "" {
+			name = entry.SummaryOverride
+		}
+		sb.WriteString(fmt.Sprintf("%d. %s %s", i+1, marker, name))
+		if entry.Primary {
+			sb.WriteString(i18n.T(lang, "calendars.primary"))
+		}
+		if entry.Id == userSettings.DefaultCalendar {
+			sb.WriteString(i18n.T(lang, "calendars.default"))
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(userSettings.CalendarRules) > 0 {
+		sb.WriteString(i18n.T(lang, "calendars.rules"))
+		for _, rule := range userSettings.CalendarRules {
+			target := rule.CalendarID
+			for _, entry := range entries {
+				if entry.Id == rule.CalendarID {
+					target = entry.Summary
+				}
+			}
+			sb.WriteString(fmt.Sprintf("• \"%s\" → %s\n", rule.Keyword, target))
+		}
+	}
+
+	sb.WriteString("\n" + i18n.T(lang, "calendars.help"))
+	return sb.String()
+}
@@ -0,0 +1,224 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"virtual-assistant/internal/calendar"
+	"virtual-assistant/internal/i18n"
+	"virtual-assistant/internal/timezone"
+)
+
+const maxAlternativeSlots = 3
+
+// pendingEvent is an event we parsed but haven't created yet because an
+// attendee name is ambiguous or it clashes with something, and the user has
+// to decide what to do.
+type pendingEvent struct {
+	title        string
+	description  string
+	startTime    string
+	endTime      string
+	attendees    []string
+	recurrence   string // Validated RRULE, empty for one-off events
+	allDay       bool   // startTime/endTime are YYYY-MM-DD, endTime inclusive
+	calendarID   string
+	conference   bool // Request a Google Meet link
+	corrected    bool // The times were taken from the message over the model's reading
+	unresolved   []ambiguousName
+	alternatives []calendar.TimeSlot
+}
+
+// checkEventConflicts returns a warning for the user and parks the event when
+// the slot overlaps existing meetings. An empty warning means the slot is free.
+func (tb *TelegramBot) checkEventConflicts(chatID int64, event *pendingEvent) (string, error) {
+	start, err := time.Parse(time.RFC3339, event.startTime)
+	if err != nil {
+		return "", fmt.Errorf("invalid start time %q: %v", event.startTime, err)
+	}
+	end, err := time.Parse(time.RFC3339, event.endTime)
+	if err != nil {
+		return "", fmt.Errorf("invalid end time %q: %v", event.endTime, err)
+	}
+
+	userSettings := tb.settings.Get(chatID)
+	report, err := tb.calendarService.CheckConflicts(userSettings.ActiveCalendars(), start, end, event.attendees, maxAlternativeSlots)
+	if err != nil {
+		return "", err
+	}
+	if !report.HasConflicts() {
+		return "", nil
+	}
+
+	event.alternatives = report.Alternatives
+
+	tb.pendingMutex.Lock()
+	tb.pendingEvents[chatID] = event
+	tb.pendingMutex.Unlock()
+
+	return formatConflictWarning(tb.LanguageFor(chatID), event, report), nil
+}
+
+// handlePendingReply resolves a parked event from the user's answer. handled is
+// false when there is nothing pending or the message isn't an answer, so the
+// message goes through the normal pipeline instead.
+func (tb *TelegramBot) handlePendingReply(ctx context.Context, chatID int64, userMessage string) (string, bool, error) {
+	tb.pendingMutex.Lock()
+	event, exists := tb.pendingEvents[chatID]
+	tb.pendingMutex.Unlock()
+	if !exists {
+		return "", false, nil
+	}
+
+	answer := strings.ToLower(strings.TrimSpace(userMessage))
+	lang := tb.LanguageFor(chatID)
+
+	if len(event.unresolved) > 0 {
+		if answer == "no" || answer == "tidak" || answer == "cancel" || answer == "batal" || answer == "/cancel" {
+			tb.clearPendingEvent(chatID)
+			return i18n.T(lang, "event.not_created"), true, nil
+		}
+		return tb.handleAttendeeChoice(ctx, chatID, event, answer)
+	}
+
+	switch answer {
+	case "yes", "y", "ya", "iya", "book anyway":
+		tb.clearPendingEvent(chatID)
+		response, err := tb.commitEvent(chatID, event)
+		return response, true, err
+	case "no", "n", "tidak", "cancel", "batal", "/cancel":
+		tb.clearPendingEvent(chatID)
+		return i18n.T(lang, "event.not_created"), true, nil
+	}
+
+	choice, err := strconv.Atoi(answer)
+	if err != nil {
+		// Anything else is a new request; drop the stale confirmation
+		tb.clearPendingEvent(chatID)
+		return "", false, nil
+	}
+	if choice < 1 || choice > len(event.alternatives) {
+		return i18n.T(lang, "conflict.pick_number", len(event.alternatives)), true, nil
+	}
+
+	slot := event.alternatives[choice-1]
+	event.startTime = slot.Start.Format(time.RFC3339)
+	event.endTime = slot.End.Format(time.RFC3339)
+
+	tb.clearPendingEvent(chatID)
+	response, err := tb.commitEvent(chatID, event)
+	return response, true, err
+}
+
+func (tb *TelegramBot) clearPendingEvent(chatID int64) {
+	tb.pendingMutex.Lock()
+	delete(tb.pendingEvents, chatID)
+	tb.pendingMutex.Unlock()
+}
+
+func (tb *TelegramBot) commitEvent(chatID int64, event *pendingEvent) (string, error) {
+	req := &calendar.EventRequest{
+		Title:       event.title,
+		Description: event.description,
+		StartTime:   event.startTime,
+		EndTime:     event.endTime,
+		AllDay:      event.allDay,
+		Attendees:   event.attendees,
+		CalendarID:  event.calendarID,
+		Conference:  event.conference,
+	}
+	if event.recurrence != "" {
+		req.Recurrence = []string{event.recurrence}
+	}
+
+	created, err := tb.calendarService.InsertEvent(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to create event: %v", err)
+	}
+
+	lang := tb.LanguageFor(chatID)
+	responseMsg := i18n.T(lang, "event.created", event.title, event.description, event.startTime, event.endTime)
+	if event.allDay {
+		responseMsg = i18n.T(lang, "event.created_all_day", event.title, event.description, event.startTime, event.endTime)
+	}
+
+	if len(event.attendees) > 0 {
+		responseMsg += i18n.T(lang, "event.attendees", strings.Join(event.attendees, ", "))
+	}
+
+	if event.recurrence != "" {
+		responseMsg += i18n.T(lang, "event.repeats", calendar.DescribeRecurrence(lang, event.recurrence))
+	}
+
+	if event.corrected {
+		responseMsg += i18n.T(lang, "event.time_corrected")
+	}
+
+	if joinURL := calendar.JoinURL(created); joinURL != "" {
+		responseMsg += i18n.T(lang, "event.join", joinURL)
+	} else if event.conference {
+		responseMsg += i18n.T(lang, "event.meet_pending")
+	}
+
+	return responseMsg, nil
+}
+
+func formatConflictWarning(lang i18n.Language, event *pendingEvent, report *calendar.ConflictReport) string {
+	loc := timezone.Location()
+
+	var sb strings.Builder
+	sb.WriteString(i18n.T(lang, "conflict.overlaps", event.title))
+	for _, conflict := range report.Conflicts {
+		timeRange := fmt.Sprintf("%s-%s", conflict.Start.In(loc).Format("15:04"), conflict.End.In(loc).Format("15:04"))
+		if conflict.Calendar == "" {
+			sb.WriteString(fmt.Sprintf("• %s (%s)\n", conflict.Summary, timeRange))
+		} else {
+			sb.WriteString(i18n.T(lang, "conflict.busy", conflict.Calendar, timeRange))
+		}
+	}
+
+	if len(report.Unavailable) > 0 {
+		sb.WriteString(i18n.T(lang, "conflict.unavailable", strings.Join(report.Unavailable, ", ")))
+	}
+
+	if len(report.Alternatives) > 0 {
+		sb.WriteString(i18n.T(lang, "conflict.alternatives"))
+		for i, slot := range report.Alternatives {
+			start := slot.Start.In(loc)
+			sb.WriteString(fmt.Sprintf("%d. %s %s-%s\n", i+1, i18n.FormatTime(lang, start, "Mon 02 Jan"), start.Format("15:04"), slot.End.In(loc).Format("15:04")))
+		}
+		sb.WriteString(i18n.T(lang, "conflict.choose"))
+	} else {
+		sb.WriteString(i18n.T(lang, "conflict.confirm"))
+	}
+
+	return sb.String()
+}
+
+// normaliseAllDayDates trims LLM date-times down to dates and makes sure the
+// range is the right way round.
+func normaliseAllDayDates(event *pendingEvent) error {
+	if len(event.startTime) > 10 {
+		event.startTime = event.startTime[:10]
+	}
+	if len(event.endTime) > 10 {
+		event.endTime = event.endTime[:10]
+	}
+
+	start, err := time.Parse("2006-01-02", event.startTime)
+	if err != nil {
+		return fmt.Errorf("invalid start date %q", event.startTime)
+	}
+	end, err := time.Parse("2006-01-02", event.endTime)
+	if err != nil {
+		return fmt.Errorf("invalid end date %q", event.endTime)
+	}
+	if end.Before(start) {
+		return fmt.Errorf("the last day is before the first day")
+	}
+
+	return nil
+}
@@ -0,0 +1,291 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"virtual-assistant/internal/contacts"
+	"virtual-assistant/internal/i18n"
+	"virtual-assistant/internal/storage"
+)
+
+const (
+	learnAttendeesDays = 90
+	maxImportSize      = 2 << 20 // 2 MiB
+	downloadTimeout    = 30 * time.Second
+)
+
+// downloadClient fetches files users send to the bot.
+var downloadClient = &http.Client{Timeout: downloadTimeout}
+
+// contactsWrites are the /contacts subcommands that change the contact book.
+// Everyone shares the book, so they're for admins; listing it is open.
+var contactsWrites = map[string]bool{"add": true, "alias": true, "group": true, "learn": true, "import": true}
+
+// refuseContactsWrite returns the reply for a sender who may not run the
+// subcommand, or "" if they may.
+func (tb *TelegramBot) refuseContactsWrite(ctx context.Context, lang i18n.Language, subcommand string) string {
+	if !contactsWrites[subcommand] {
+		return ""
+	}
+	userID := senderOf(ctx)
+	role := tb.roleOf(userID)
+	if role.AtLeast(storage.RoleAdmin) {
+		return ""
+	}
+	command := "/contacts " + subcommand
+	logger.WarnContext(ctx, "refused contacts command", "command", command, "user_id", userID, "role", role)
+	return i18n.T(lang, "admin.admin_only", command)
+}
+
+// ambiguousName is an attendee name that matched several contacts and is
+// waiting for the user to pick one.
+type ambiguousName struct {
+	name       string
+	candidates []contacts.Contact
+}
+
+// resolveAttendees turns the LLM's ATTENDEES field into emails. Names that
+// match several contacts are parked on the event; unknown names are returned
+// so the caller can ask for an address.
+func (tb *TelegramBot) resolveAttendees(attendeesStr string, event *pendingEvent) []string {
+	var unknown []string
+	for _, name := range contacts.SplitNames(attendeesStr) {
+		match := tb.contacts.Resolve(name)
+		switch {
+		case match.Resolved():
+			event.attendees = appendUnique(event.attendees, match.Emails...)
+		case match.Ambiguous():
+			event.unresolved = append(event.unresolved, ambiguousName{name: name, candidates: match.Candidates})
+		default:
+			unknown = append(unknown, name)
+		}
+	}
+	return unknown
+}
+
+// handleAttendeeChoice consumes the user's pick for the first ambiguous name.
+// handled is false when the answer isn't a number, like handlePendingReply.
+func (tb *TelegramBot) handleAttendeeChoice(ctx context.Context, chatID int64, event *pendingEvent, answer string) (string, bool, error) {
+	current := event.unresolved[0]
+	lang := tb.LanguageFor(chatID)
+
+	choice, err := strconv.Atoi(strings.TrimSpace(answer))
+	if err != nil {
+		// Anything else is a new request; drop the half-made event
+		tb.clearPendingEvent(chatID)
+		return "", false, nil
+	}
+	if choice < 1 || choice > len(current.candidates) {
+		return i18n.T(lang, "attendees.pick_number", len(current.candidates), formatAttendeeQuestion(lang, current)), true, nil
+	}
+
+	event.attendees = appendUnique(event.attendees, current.candidates[choice-1].Email)
+	event.unresolved = event.unresolved[1:]
+
+	if len(event.unresolved) > 0 {
+		return formatAttendeeQuestion(lang, event.unresolved[0]), true, nil
+	}
+
+	// Everyone is known now, carry on as if they'd been resolved straight away
+	tb.clearPendingEvent(chatID)
+	response, err := tb.finishEvent(ctx, chatID, event)
+	return response, true, err
+}
+
+func formatAttendeeQuestion(lang i18n.Language, ambiguous ambiguousName) string {
+	var sb strings.Builder
+	sb.WriteString(i18n.T(lang, "attendees.which", ambiguous.name))
+	for i, candidate := range ambiguous.candidates {
+		sb.WriteString(fmt.Sprintf("%d. %s (%s)\n", i+1, candidate.Name, candidate.Email))
+	}
+	return sb.String()
+}
+
+func (tb *TelegramBot) handleContactsCommand(ctx context.Context, lang i18n.Language, args string) (string, error) {
+	contactsHelp := i18n.T(lang, "contacts.help")
+
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		return formatContactList(lang, tb.contacts), nil
+	}
+
+	subcommand := strings.ToLower(fields[0])
+	if refusal := tb.refuseContactsWrite(ctx, lang, subcommand); refusal != "" {
+		return refusal, nil
+	}
+
+	switch subcommand {
+	case "add":
+		if len(fields) < 3 || !strings.Contains(fields[len(fields)-1], "@") {
+			return contactsHelp, nil
+		}
+		name := strings.Join(fields[1:len(fields)-1], " ")
+		email := fields[len(fields)-1]
+		if err := tb.contacts.Add(contacts.Contact{Name: name, Email: email}); err != nil {
+			return "", err
+		}
+		return i18n.T(lang, "contacts.added", name, email), nil
+
+	case "alias":
+		if len(fields) < 3 {
+			return contactsHelp, nil
+		}
+		if err := tb.contacts.AddAlias(fields[1], strings.Join(fields[2:], " ")); err != nil {
+			return fmt.Sprintf("❌ %v", err), nil
+		}
+		return i18n.T(lang, "contacts.alias_added", fields[1], strings.Join(fields[2:], " ")), nil
+
+	case "group":
+		if len(fields) < 3 {
+			return contactsHelp, nil
+		}
+		var members, unknown []string
+		for _, name := range contacts.SplitNames(strings.Join(fields[2:], " ")) {
+			match := tb.contacts.Resolve(name)
+			if match.Resolved() {
+				members = appendUnique(members, match.Emails...)
+			} else {
+				unknown = append(unknown, name)
+			}
+		}
+		if len(unknown) > 0 {
+			return i18n.T(lang, "contacts.unresolved", strings.Join(unknown, ", ")), nil
+		}
+		if err := tb.contacts.SetGroup(fields[1], members); err != nil {
+			return "", err
+		}
+		return i18n.T(lang, "contacts.group_set", fields[1], len(members)), nil
+
+	case "learn":
+		learned, err := tb.LearnContactsFromCalendar()
+		if err != nil {
+			return "", err
+		}
+		return i18n.T(lang, "contacts.learned", learned, learnAttendeesDays), nil
+
+	case "import":
+		return i18n.T(lang, "contacts.import_hint"), nil
+	}
+
+	return contactsHelp, nil
+}
+
+// LearnContactsFromCalendar adds everyone we recently met with to the contact
+// book. Names the user set themselves are never overwritten.
+func (tb *TelegramBot) LearnContactsFromCalendar() (int, error) {
+	attendees, err := tb.calendarService.RecentAttendees([]string{"primary"}, learnAttendeesDays)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load past attendees: %v", err)
+	}
+
+	var learned []contacts.Contact
+	for _, attendee := range attendees {
+		learned = append(learned, contacts.Contact{
+			Name:    attendee.DisplayName,
+			Email:   attendee.Email,
+			Learned: true,
+		})
+	}
+	if len(learned) == 0 {
+		return 0, nil
+	}
+
+	if err := tb.contacts.AddAll(learned); err != nil {
+		return 0, err
+	}
+	logger.Info("learned contacts from past meetings", "count", len(learned))
+	return len(learned), nil
+}
+
+// handleContactsImport downloads a document sent with /contacts import and
+// merges it into the contact book.
+func (tb *TelegramBot) handleContactsImport(ctx context.Context, chatID int64, document *tgbotapi.Document) (string, error) {
+	lang := tb.LanguageFor(chatID)
+	if refusal := tb.refuseContactsWrite(ctx, lang, "import"); refusal != "" {
+		return refusal, nil
+	}
+	if document.FileSize > maxImportSize {
+		return i18n.T(lang, "contacts.too_large"), nil
+	}
+
+	fileURL, err := tb.bot.GetFileDirectURL(document.FileID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get file URL: %v", err)
+	}
+
+	// The file URL contains the bot token, so it's kept out of errors
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, nil)
+	if err != nil {
+		return "", errors.New("failed to download contacts file: invalid file URL")
+	}
+	resp, err := downloadClient.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return "", fmt.Errorf("failed to download contacts file: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download contacts file: %s", resp.Status)
+	}
+
+	result, err := tb.contacts.Import(document.FileName, http.MaxBytesReader(nil, resp.Body, maxImportSize))
+	if err != nil {
+		return i18n.T(lang, "contacts.import_failed", err), nil
+	}
+
+	return i18n.T(lang, "contacts.imported", result.Contacts, result.Groups), nil
+}
+
+func formatContactList(lang i18n.Language, book *contacts.Book) string {
+	allContacts := book.Contacts()
+	groups := book.Groups()
+	if len(allContacts) == 0 && len(groups) == 0 {
+		return i18n.T(lang, "contacts.empty") + i18n.T(lang, "contacts.help")
+	}
+
+	var sb strings.Builder
+	sb.WriteString(i18n.T(lang, "contacts.header", len(allContacts)))
+	for _, contact := range allContacts {
+		sb.WriteString(fmt.Sprintf("• %s <%s>", contact.Name, contact.Email))
+		if len(contact.Aliases) > 0 {
+			sb.WriteString(i18n.T(lang, "contacts.aka", strings.Join(contact.Aliases, ", ")))
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(groups) > 0 {
+		sb.WriteString(i18n.T(lang, "contacts.groups"))
+		for _, group := range groups {
+			sb.WriteString(fmt.Sprintf("• %s (%d)\n", group.Name, len(group.Members)))
+		}
+	}
+
+	return sb.String()
+}
+
+func appendUnique(list []string, values ...string) []string {
+	for _, value := range values {
+		exists := false
+		for _, existing := range list {
+			if strings.EqualFold(existing, value) {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			list = append(list, value)
+		}
+	}
+	return list
+}
@@ -0,0 +1,143 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"virtual-assistant/internal/dateparse"
+	"virtual-assistant/internal/i18n"
+	"virtual-assistant/internal/timezone"
+)
+
+const defaultEventDuration = time.Hour
+
+var (
+	agendaWords  = regexp.MustCompile(`(?i)\b(agenda|schedule|meetings?|jadwal|acara|rapat)\b`)
+	createPrefix = regexp.MustCompile(`(?i)^(?:please\s+|tolong\s+)?(?:create|schedule|add|book|set up|buat(?:kan)?|bikin(?:kan)?|jadwalkan|tambah(?:kan)?)\s+(?:an?\s+|the\s+)?`)
+
+	// untilPattern marks where a message stops describing the event itself:
+	// "until 15 December", "sampai akhir bulan", "remind me 30 minutes
+	// before", "ingatkan 1 jam sebelumnya".
+	untilPattern = regexp.MustCompile(`(?i)(?:\b\d+\s*(?:minutes?|mins?|hours?|hrs?|days?|menit|jam|hari)\s+)?\b(?:until|till|sampai|hingga|before|sebelum(?:nya)?)\b`)
+)
+
+// correctEventTimes checks the LLM's START_TIME/END_TIME against what the
+// user actually wrote. Whenever the deterministic parser found an explicit
+// date, time or duration that disagrees with the model, the parser wins and
+// event.corrected is set so the user is told. A bare "5/6" isn't taken as a
+// date, and an hour without am/pm keeps the model's half of the day.
+// Recurring events are left alone: their dates describe the series, not the
+// first occurrence.
+func correctEventTimes(ctx context.Context, userMessage string, event *pendingEvent) {
+	if event.allDay || event.recurrence != "" || userMessage == "" {
+		return
+	}
+
+	loc := timezone.Location()
+	now := time.Now().In(loc)
+	parsed := dateparse.Parse(userMessage, now)
+	if i := untilPattern.FindStringIndex(userMessage); i != nil {
+		// Dates and durations after "until"/"before" belong to something
+		// else; a time range such as "2 until 4pm" still starts in front
+		head := dateparse.Parse(userMessage[:i[0]], now)
+		parsed.HasDate, parsed.Date = head.HasDate, head.Date
+		parsed.HasDuration, parsed.Duration = head.HasDuration, head.Duration
+		parsed.HasTime = parsed.HasTime && head.HasTime
+	}
+	if parsed.DateGuessed {
+		parsed.HasDate = false
+	}
+	if !parsed.HasDate && !parsed.HasTime && !parsed.HasDuration {
+		return
+	}
+
+	start, startErr := time.Parse(time.RFC3339, event.startTime)
+	end, endErr := time.Parse(time.RFC3339, event.endTime)
+
+	duration := defaultEventDuration
+	if startErr == nil && endErr == nil && end.After(start) {
+		duration = end.Sub(start)
+	}
+	if parsed.HasDuration {
+		duration = parsed.Duration
+	}
+
+	corrected := start.In(loc)
+	switch {
+	case startErr != nil:
+		// Nothing usable from the model, fall back to the parser entirely
+		parsedStart, ok := parsed.Start(now)
+		if !ok {
+			return
+		}
+		corrected = parsedStart
+	case parsed.HasTime:
+		day := corrected
+		if parsed.HasDate {
+			day = parsed.Date
+		}
+		hour := parsed.Hour
+		if parsed.TimeGuessed {
+			// "at 8" said about the evening is for the model to judge
+			hour = parsed.Hour%12 + corrected.Hour()/12*12
+		}
+		corrected = time.Date(day.Year(), day.Month(), day.Day(), hour, parsed.Minute, 0, 0, loc)
+	case parsed.HasDate:
+		corrected = time.Date(parsed.Date.Year(), parsed.Date.Month(), parsed.Date.Day(),
+			corrected.Hour(), corrected.Minute(), 0, 0, loc)
+	}
+
+	// A misread is more likely than a request to book something already over
+	if startErr == nil && start.After(now) && corrected.Before(now) {
+		return
+	}
+
+	startTime := corrected.Format(time.RFC3339)
+	endTime := corrected.Add(duration).Format(time.RFC3339)
+	if startErr != nil || endErr != nil || !corrected.Equal(start) || !corrected.Add(duration).Equal(end) {
+		logger.InfoContext(ctx, "corrected event time from the message", "llm_start", event.startTime, "llm_end", event.endTime, "start", startTime, "end", endTime)
+		event.corrected = startErr == nil && endErr == nil
+	}
+	event.startTime = startTime
+	event.endTime = endTime
+}
+
+// offlineIntent handles the simplest requests without the LLM, for when it
+// is unavailable: "meeting with Budi tomorrow at 3pm", "besok jam 10 rapat
+// tim" and "what's on today". It answers in the same ACTION format so the
+// usual handlers take it from there.
+func offlineIntent(lang i18n.Language, userMessage string) (string, bool) {
+	loc := timezone.Location()
+	now := time.Now().In(loc)
+	parsed := dateparse.Parse(userMessage, now)
+
+	isToday := !parsed.HasDate || parsed.Date.Equal(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc))
+	if !parsed.HasTime {
+		if isToday && agendaWords.MatchString(userMessage) {
+			return "ACTION: CHECK_TODAY", true
+		}
+		return "", false
+	}
+
+	// Questions about a time ("what's at 3pm?") aren't requests to book one
+	if strings.Contains(userMessage, "?") {
+		return "", false
+	}
+
+	start, _ := parsed.Start(now)
+	duration := defaultEventDuration
+	if parsed.HasDuration {
+		duration = parsed.Duration
+	}
+
+	title := strings.TrimSpace(createPrefix.ReplaceAllString(parsed.Rest, ""))
+	if title == "" {
+		title = i18n.T(lang, "event.default_title")
+	}
+
+	return fmt.Sprintf("ACTION: CREATE_EVENT\nTITLE: %s\nDESCRIPTION: \nSTART_TIME: %s\nEND_TIME: %s\n",
+		title, start.Format(time.RFC3339), start.Add(duration).Format(time.RFC3339)), true
+}
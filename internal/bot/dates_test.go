@@ -0,0 +1,102 @@
+package bot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"virtual-assistant/internal/timezone"
+)
+
+func TestCorrectEventTimes(t *testing.T) {
+	loc := timezone.Location()
+	now := time.Now().In(loc)
+	tomorrow := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, loc)
+	at := func(day time.Time, hour int) string {
+		return day.Add(time.Duration(hour) * time.Hour).Format(time.RFC3339)
+	}
+
+	tests := []struct {
+		name       string
+		message    string
+		recurrence string
+		llmStart   string
+		llmEnd     string
+		wantStart  string
+		wantEnd    string
+		corrected  bool
+	}{
+		{
+			name:      "agrees with the model",
+			message:   "lunch tomorrow at 1pm",
+			llmStart:  at(tomorrow, 13),
+			llmEnd:    at(tomorrow, 14),
+			wantStart: at(tomorrow, 13),
+			wantEnd:   at(tomorrow, 14),
+		},
+		{
+			name:      "explicit date and time win",
+			message:   "lunch tomorrow at 1pm",
+			llmStart:  at(tomorrow.AddDate(0, 0, 1), 12),
+			llmEnd:    at(tomorrow.AddDate(0, 0, 1), 14),
+			wantStart: at(tomorrow, 13),
+			wantEnd:   at(tomorrow, 15),
+			corrected: true,
+		},
+		{
+			name:      "hour without am/pm keeps the model's half of the day",
+			message:   "dinner in room 3 tomorrow at 8",
+			llmStart:  at(tomorrow, 20),
+			llmEnd:    at(tomorrow, 21),
+			wantStart: at(tomorrow, 20),
+			wantEnd:   at(tomorrow, 21),
+		},
+		{
+			name:      "bare numeric date is ignored",
+			message:   "review the 5/6 split with Budi tomorrow at 9am",
+			llmStart:  at(tomorrow, 9),
+			llmEnd:    at(tomorrow, 10),
+			wantStart: at(tomorrow, 9),
+			wantEnd:   at(tomorrow, 10),
+		},
+		{
+			name:      "duration before \"before\" is a reminder",
+			message:   "meeting tomorrow at 3pm, remind me 30 minutes before",
+			llmStart:  at(tomorrow, 15),
+			llmEnd:    at(tomorrow, 16),
+			wantStart: at(tomorrow, 15),
+			wantEnd:   at(tomorrow, 16),
+		},
+		{
+			name:       "recurring events are left alone",
+			message:    "every Tuesday at 10 until 15 December",
+			recurrence: "RRULE:FREQ=WEEKLY;BYDAY=TU",
+			llmStart:   at(tomorrow, 10),
+			llmEnd:     at(tomorrow, 11),
+			wantStart:  at(tomorrow, 10),
+			wantEnd:    at(tomorrow, 11),
+		},
+		{
+			name:      "a future start isn't moved into the past",
+			message:   "call yesterday at 9am",
+			llmStart:  at(tomorrow, 9),
+			llmEnd:    at(tomorrow, 10),
+			wantStart: at(tomorrow, 9),
+			wantEnd:   at(tomorrow, 10),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := &pendingEvent{startTime: tt.llmStart, endTime: tt.llmEnd, recurrence: tt.recurrence}
+			correctEventTimes(context.Background(), tt.message, event)
+
+			if event.startTime != tt.wantStart || event.endTime != tt.wantEnd {
+				t.Errorf("got %s - %s, want %s - %s", event.startTime, event.endTime, tt.wantStart, tt.wantEnd)
+			}
+			if event.corrected != tt.corrected {
+				t.Errorf("corrected = %t, want %t", event.corrected, tt.corrected)
+			}
+		})
+	}
+}
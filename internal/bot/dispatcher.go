@@ -0,0 +1,260 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"virtual-assistant/internal/logging"
+)
+
+const (
+	DefaultWorkers       = 8
+	DefaultQueueSize     = 100
+	DefaultUpdateTimeout = 2 * time.Minute
+
+	// How long a webhook request waits for room in a full queue
+	webhookQueueWait = 5 * time.Second
+
+	// How long cancelled handlers get to return once draining times out
+	cancelGrace = 5 * time.Second
+)
+
+var (
+	// ErrQueueFull is returned when an update can't be queued before its
+	// context is done.
+	ErrQueueFull = errors.New("update queue is full")
+
+	// ErrStopped is returned for updates that arrive while shutting down.
+	ErrStopped = errors.New("bot is shutting down")
+)
+
+// dispatcher runs updates on a fixed pool of workers. Updates from the same
+// chat are handled one at a time in the order they arrived, so a slow Claude
+// call only holds up the chat that made it.
+type dispatcher struct {
+	handle  func(ctx context.Context, update tgbotapi.Update)
+	workers int
+	timeout time.Duration
+
+	slots chan struct{} // One per queued or running job
+	ready chan int64    // Chats with jobs waiting and no worker on them
+
+	mu      sync.Mutex
+	chats   map[int64][]job // Head of each queue is the job being handled
+	stopped bool
+	pending sync.WaitGroup // Queued and running updates
+
+	// Handlers run under base, so cancelling it kills their claude processes
+	base   context.Context
+	cancel context.CancelFunc
+
+	start sync.Once
+}
+
+// job is an update to handle, or a call queued with Do.
+type job struct {
+	update tgbotapi.Update
+	fn     func(ctx context.Context) // Runs instead of handle when set
+	caller context.Context           // Do's ctx, which also cancels fn
+	done   chan struct{}             // Closed once fn has run or been dropped
+}
+
+func newDispatcher(handle func(ctx context.Context, update tgbotapi.Update), workers, queueSize int, timeout time.Duration) *dispatcher {
+	if workers < 1 {
+		workers = DefaultWorkers
+	}
+	if queueSize < 1 {
+		queueSize = DefaultQueueSize
+	}
+	if timeout <= 0 {
+		timeout = DefaultUpdateTimeout
+	}
+
+	base, cancel := context.WithCancel(context.Background())
+	return &dispatcher{
+		handle:  handle,
+		workers: workers,
+		timeout: timeout,
+		slots:   make(chan struct{}, queueSize),
+		ready:   make(chan int64, queueSize),
+		chats:   make(map[int64][]job),
+		base:    base,
+		cancel:  cancel,
+	}
+}
+
+// Submit queues an update, waiting for room while the queue is full. It
+// gives up with ErrQueueFull once ctx is done.
+func (d *dispatcher) Submit(ctx context.Context, update tgbotapi.Update) error {
+	updatesReceived.WithLabelValues(updateType(update)).Inc()
+	return d.enqueue(ctx, updateChatID(update), job{update: update})
+}
+
+// Do runs fn in chatID's queue, after the updates already waiting there and
+// before any that arrive later, and waits for it. fn gets the same deadline
+// as an update, and is cancelled as well when ctx is done.
+func (d *dispatcher) Do(ctx context.Context, chatID int64, fn func(ctx context.Context)) error {
+	done := make(chan struct{})
+	ran := false
+	run := func(ctx context.Context) {
+		ran = true
+		fn(ctx)
+	}
+	if err := d.enqueue(ctx, chatID, job{fn: run, caller: ctx, done: done}); err != nil {
+		return err
+	}
+
+	select {
+	case <-done:
+		if !ran {
+			return ErrStopped // Dropped by a shutdown before it started
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (d *dispatcher) enqueue(ctx context.Context, chatID int64, j job) error {
+	d.start.Do(func() {
+		for i := 0; i < d.workers; i++ {
+			go d.work()
+		}
+	})
+
+	select {
+	case d.slots <- struct{}{}:
+	case <-ctx.Done():
+		return ErrQueueFull
+	}
+
+	d.mu.Lock()
+	if d.stopped {
+		d.mu.Unlock()
+		<-d.slots
+		return ErrStopped
+	}
+	d.pending.Add(1)
+	updateQueueDepth.Inc()
+	d.chats[chatID] = append(d.chats[chatID], j)
+	idle := len(d.chats[chatID]) == 1
+	d.mu.Unlock()
+
+	// ready can hold every queued update, so this never blocks
+	if idle {
+		d.ready <- chatID
+	}
+	return nil
+}
+
+func (d *dispatcher) work() {
+	for chatID := range d.ready {
+		for {
+			d.mu.Lock()
+			j := d.chats[chatID][0]
+			d.mu.Unlock()
+
+			switch {
+			case j.caller != nil && j.caller.Err() != nil:
+				// Do gave up waiting, so nobody wants the result
+			case d.base.Err() == nil:
+				d.run(j)
+			default:
+				logger.Warn("dropping update: shut down before it was handled", "update_id", j.update.UpdateID)
+			}
+			if j.done != nil {
+				close(j.done)
+			}
+			<-d.slots
+			d.pending.Done()
+			updateQueueDepth.Dec()
+
+			d.mu.Lock()
+			remaining := d.chats[chatID][1:]
+			if len(remaining) == 0 {
+				delete(d.chats, chatID)
+			} else {
+				d.chats[chatID] = remaining
+			}
+			d.mu.Unlock()
+
+			if len(remaining) == 0 {
+				break
+			}
+		}
+	}
+}
+
+// run handles one job with a deadline. A panic is logged and the worker
+// carries on with the next job.
+func (d *dispatcher) run(j job) {
+	ctx, cancel := context.WithTimeout(d.base, d.timeout)
+	defer cancel()
+	if j.caller != nil {
+		stop := context.AfterFunc(j.caller, cancel)
+		defer stop()
+	}
+	ctx = logging.WithRequestID(ctx, logging.NewRequestID())
+
+	defer func() {
+		if r := recover(); r != nil {
+			updatePanics.Inc()
+			logger.ErrorContext(ctx, "panic handling update", "update_id", j.update.UpdateID, "panic", fmt.Sprint(r), "stack", string(debug.Stack()))
+		}
+	}()
+
+	if j.fn != nil {
+		j.fn(ctx)
+		return
+	}
+	d.handle(ctx, j.update)
+}
+
+// Stop refuses new updates and waits for queued and running ones to finish.
+// When ctx is done first, the remaining handlers are cancelled and
+// updates still in the queue are dropped.
+func (d *dispatcher) Stop(ctx context.Context) error {
+	d.mu.Lock()
+	d.stopped = true
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.pending.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		d.cancel()
+		return nil
+	case <-ctx.Done():
+	}
+
+	d.cancel()
+	select {
+	case <-done:
+	case <-time.After(cancelGrace):
+		logger.Warn("some handlers didn't return after being cancelled")
+	}
+	return ctx.Err()
+}
+
+// updateChatID returns the chat an update belongs to. Updates without one
+// share chat 0.
+func updateChatID(update tgbotapi.Update) int64 {
+	switch {
+	case update.Message != nil:
+		return update.Message.Chat.ID
+	case update.CallbackQuery != nil && update.CallbackQuery.Message != nil:
+		return update.CallbackQuery.Message.Chat.ID
+	case update.CallbackQuery != nil:
+		return update.CallbackQuery.From.ID
+	}
+	return 0
+}
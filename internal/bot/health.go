@@ -0,0 +1,64 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Webhook delivery errors older than this no longer say anything about now
+const webhookErrorWindow = 10 * time.Minute
+
+// Health checks that the bot token still works and that updates can reach
+// the bot: in webhook mode the webhook must be registered at our URL, in
+// polling mode the polling loop must be running and no webhook may be set,
+// since Telegram refuses getUpdates while one is.
+func (tb *TelegramBot) Health(ctx context.Context) (string, error) {
+	// The library takes no context, so give up waiting rather than block the probe
+	type result struct {
+		me   tgbotapi.User
+		info tgbotapi.WebhookInfo
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		var r result
+		if r.me, r.err = tb.bot.GetMe(); r.err != nil {
+			r.err = fmt.Errorf("getMe failed: %v", r.err)
+		} else if r.info, r.err = tb.bot.GetWebhookInfo(); r.err != nil {
+			r.err = fmt.Errorf("getWebhookInfo failed: %v", r.err)
+		}
+		done <- r
+	}()
+
+	var r result
+	select {
+	case r = <-done:
+	case <-ctx.Done():
+		return "", fmt.Errorf("telegram API unreachable: %v", ctx.Err())
+	}
+	if r.err != nil {
+		return "", r.err
+	}
+
+	if tb.webhookURL == "" {
+		if r.info.URL != "" {
+			return "", fmt.Errorf("a webhook is set, so polling gets no updates")
+		}
+		if !tb.polling.Load() {
+			return "", fmt.Errorf("polling has stopped")
+		}
+		return fmt.Sprintf("@%s, polling", r.me.UserName), nil
+	}
+
+	if r.info.URL != tb.webhookURL+"/webhook" {
+		return "", fmt.Errorf("webhook is not registered at %s/webhook", tb.webhookURL)
+	}
+	detail := fmt.Sprintf("@%s, webhook, %d pending", r.me.UserName, r.info.PendingUpdateCount)
+	if lastError := time.Unix(int64(r.info.LastErrorDate), 0); r.info.LastErrorDate != 0 && time.Since(lastError) < webhookErrorWindow {
+		detail += fmt.Sprintf(", last delivery error %s ago: %s", time.Since(lastError).Round(time.Second), r.info.LastErrorMessage)
+	}
+	return detail, nil
+}
@@ -0,0 +1,211 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	gcal "google.golang.org/api/calendar/v3"
+	"virtual-assistant/internal/calendar"
+	"virtual-assistant/internal/i18n"
+	"virtual-assistant/internal/markup"
+	"virtual-assistant/internal/storage"
+	"virtual-assistant/internal/timezone"
+)
+
+// Invitation callbacks look like "inv:<a|t|d>:<event ID>". Telegram caps
+// callback data at 64 bytes, which fits normal and recurring instance IDs.
+const (
+	invitationCallbackPrefix = "inv:"
+	maxCallbackDataLength    = 64
+)
+
+// After answering an invitation, a plain message only counts as the note for
+// a few minutes; "/note <text>" works for longer.
+const (
+	noteReplyWindow = 5 * time.Minute
+	noteTTL         = time.Hour
+)
+
+// Callback codes to attendee statuses; the status doubles as the catalog key
+// suffix for the confirmation ("invitation.accepted", ...).
+var invitationResponses = map[string]string{
+	"a": "accepted",
+	"t": "tentative",
+	"d": "declined",
+}
+
+// pendingNote remembers an invitation we just answered so the user's next
+// message can be attached as a note for the organiser.
+type pendingNote struct {
+	eventID    string
+	calendarID string
+	summary    string
+	answeredAt time.Time
+}
+
+// SendInvitation pushes an invite card with Accept / Tentative / Decline
+// buttons for an event we haven't answered yet.
+func (tb *TelegramBot) SendInvitation(chatID int64, event *gcal.Event) error {
+	lang := tb.LanguageFor(chatID)
+	text := formatInvitation(lang, event)
+
+	if len(invitationCallbackPrefix)+2+len(event.Id) > maxCallbackDataLength {
+		_, err := tb.sendHTML(chatID, text+markup.Escape(i18n.T(lang, "invitation.answer_in_google")), nil)
+		return err
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "button.accept"), invitationCallbackPrefix+"a:"+event.Id),
+		tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "button.maybe"), invitationCallbackPrefix+"t:"+event.Id),
+		tgbotapi.NewInlineKeyboardButtonData(i18n.T(lang, "button.decline"), invitationCallbackPrefix+"d:"+event.Id),
+	))
+	_, err := tb.sendHTML(chatID, text, &keyboard)
+	return err
+}
+
+func (tb *TelegramBot) handleCallbackQuery(ctx context.Context, query *tgbotapi.CallbackQuery) {
+	if !strings.HasPrefix(query.Data, invitationCallbackPrefix) || query.Message == nil {
+		tb.bot.Request(tgbotapi.NewCallback(query.ID, ""))
+		return
+	}
+
+	chatID := query.Message.Chat.ID
+	lang := tb.LanguageFor(chatID)
+
+	// The buttons answer for the owner's calendar
+	if query.From == nil || tb.roleOf(query.From.ID) != storage.RoleOwner {
+		logger.WarnContext(ctx, "refused invitation answer from a non-owner", "chat_id", chatID)
+		tb.bot.Request(tgbotapi.NewCallback(query.ID, i18n.T(lang, "invitation.owner_only")))
+		return
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(query.Data, invitationCallbackPrefix), ":", 2)
+	status, known := invitationResponses[parts[0]]
+	if len(parts) != 2 || !known {
+		tb.bot.Request(tgbotapi.NewCallback(query.ID, i18n.T(lang, "invitation.unknown")))
+		return
+	}
+	eventID := parts[1]
+
+	event, calendarID, err := tb.respondToInvitation(chatID, eventID, status, "")
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to answer invitation", "event_id", eventID, "error", err)
+		tb.bot.Request(tgbotapi.NewCallback(query.ID, i18n.T(lang, "invitation.failed")))
+		return
+	}
+	label := i18n.T(lang, "invitation."+status)
+	tb.bot.Request(tgbotapi.NewCallback(query.ID, label))
+
+	// Replace the buttons with the answer so the card can't be tapped twice
+	edit := tgbotapi.NewEditMessageText(chatID, query.Message.MessageID, string(formatInvitation(lang, event)+"\n\n"+markup.Escape(label)))
+	edit.ParseMode = tgbotapi.ModeHTML
+	tb.bot.Send(edit)
+
+	tb.pendingMutex.Lock()
+	tb.pendingNotes[chatID] = &pendingNote{eventID: eventID, calendarID: calendarID, summary: event.Summary, answeredAt: time.Now()}
+	tb.pendingMutex.Unlock()
+
+	tb.sendHTML(chatID, markup.Escape(i18n.T(lang, "invitation.note_prompt")), nil)
+}
+
+// respondToInvitation answers on whichever of the chat's calendars has the
+// event, since cards only carry the event ID. It returns that calendar.
+func (tb *TelegramBot) respondToInvitation(chatID int64, eventID, status, comment string) (*gcal.Event, string, error) {
+	var lastErr error
+	for _, calendarID := range tb.CalendarsFor(chatID) {
+		event, err := tb.calendarService.RespondToInvitation(calendarID, eventID, status, comment)
+		if err == nil {
+			return event, calendarID, nil
+		}
+		lastErr = err
+	}
+	return nil, "", lastErr
+}
+
+// handleNoteReply attaches the user's message to the invitation they just
+// answered: a plain message right after the card, or "/note <text>" within
+// noteTTL. Anything else goes through the normal pipeline, and other commands
+// drop the note.
+func (tb *TelegramBot) handleNoteReply(chatID int64, userMessage string) (string, bool, error) {
+	lang := tb.LanguageFor(chatID)
+	message := strings.TrimSpace(userMessage)
+	command := commandOf(message)
+
+	tb.pendingMutex.Lock()
+	note, exists := tb.pendingNotes[chatID]
+	if exists && time.Since(note.answeredAt) > noteTTL {
+		delete(tb.pendingNotes, chatID)
+		exists = false
+	}
+	tb.pendingMutex.Unlock()
+	if !exists {
+		if command == "/note" {
+			return i18n.T(lang, "invitation.no_pending_note"), true, nil
+		}
+		return "", false, nil
+	}
+
+	text := message
+	switch {
+	case command == "/note":
+		text = commandArgs(message)
+		if text == "" {
+			return i18n.T(lang, "invitation.note_usage"), true, nil
+		}
+	case strings.EqualFold(message, "/skip"):
+		tb.clearPendingNote(chatID)
+		return i18n.T(lang, "invitation.no_note"), true, nil
+	case strings.HasPrefix(message, "/"):
+		tb.clearPendingNote(chatID)
+		return "", false, nil
+	case message == "" || time.Since(note.answeredAt) > noteReplyWindow:
+		// Probably about something else by now; /note still works
+		return "", false, nil
+	}
+
+	tb.clearPendingNote(chatID)
+	if _, err := tb.calendarService.RespondToInvitation(note.calendarID, note.eventID, "", text); err != nil {
+		return "", true, err
+	}
+	return i18n.T(lang, "invitation.note_added", note.summary), true, nil
+}
+
+func (tb *TelegramBot) clearPendingNote(chatID int64) {
+	tb.pendingMutex.Lock()
+	delete(tb.pendingNotes, chatID)
+	tb.pendingMutex.Unlock()
+}
+
+func formatInvitation(lang i18n.Language, event *gcal.Event) markup.HTML {
+	var sb strings.Builder
+	sb.WriteString(string(markup.Sprintf(i18n.T(lang, "invitation.header"), markup.Bold(event.Summary))))
+
+	loc := timezone.Location()
+	if calendar.IsAllDay(event) {
+		sb.WriteString(string(markup.Sprintf(i18n.T(lang, "invitation.all_day"), event.Start.Date)))
+	} else if start, err := time.Parse(time.RFC3339, event.Start.DateTime); err == nil {
+		end, _ := time.Parse(time.RFC3339, event.End.DateTime)
+		sb.WriteString(string(markup.Sprintf("🕐 %s-%s\n", i18n.FormatTime(lang, start.In(loc), "Mon 02 Jan 15:04"), end.In(loc).Format("15:04"))))
+	}
+
+	if event.Organizer != nil {
+		organizer := event.Organizer.DisplayName
+		if organizer == "" {
+			organizer = event.Organizer.Email
+		}
+		sb.WriteString(string(markup.Sprintf(i18n.T(lang, "invitation.from"), organizer)))
+	}
+	if event.Location != "" {
+		sb.WriteString(string(markup.Sprintf("📍 %s\n", event.Location)))
+	}
+	if joinURL := calendar.JoinURL(event); joinURL != "" {
+		sb.WriteString(string(markup.Escape(i18n.T(lang, "invitation.online"))))
+	}
+	if event.Description != "" {
+		sb.WriteString(string(markup.Sprintf("\n📝 %s\n", event.Description)))
+	}
+
+	return markup.HTML(strings.TrimRight(sb.String(), "\n"))
+}
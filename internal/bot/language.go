@@ -0,0 +1,53 @@
+package bot
+
+import (
+	"context"
+
+	"virtual-assistant/internal/i18n"
+	"virtual-assistant/internal/settings"
+)
+
+// LanguageFor returns the language the chat is answered in. Without a
+// settings store every chat gets the default.
+func (tb *TelegramBot) LanguageFor(chatID int64) i18n.Language {
+	if tb.settings == nil {
+		return i18n.Default
+	}
+	userSettings := tb.settings.Get(chatID)
+	if lang, ok := i18n.Parse(userSettings.Language); ok {
+		return lang
+	}
+	return i18n.Default
+}
+
+// rememberLanguage picks the chat's language from the Telegram client's
+// language_code the first time we hear from it. /lang always wins.
+func (tb *TelegramBot) rememberLanguage(ctx context.Context, chatID int64, languageCode string) {
+	if languageCode == "" || tb.settings.Get(chatID).Language != "" {
+		return
+	}
+
+	lang := i18n.FromTelegram(languageCode)
+	err := tb.settings.Update(chatID, func(us *settings.UserSettings) {
+		us.Language = string(lang)
+	})
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to save language", "chat_id", chatID, "error", err)
+	}
+}
+
+func (tb *TelegramBot) handleLangCommand(chatID int64, args string) (string, error) {
+	lang, ok := i18n.Parse(args)
+	if !ok {
+		current := tb.LanguageFor(chatID)
+		return i18n.T(current, "lang.current", i18n.T(current, "lang.name")), nil
+	}
+
+	err := tb.settings.Update(chatID, func(us *settings.UserSettings) {
+		us.Language = string(lang)
+	})
+	if err != nil {
+		return "", err
+	}
+	return i18n.T(lang, "lang.set"), nil
+}
@@ -0,0 +1,33 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"virtual-assistant/internal/logging"
+)
+
+var (
+	logger = logging.For("bot")
+
+	// apiLogger gets the Telegram library's own output. Set its level to
+	// debug to see every request and response.
+	apiLogger = logging.For("telegram")
+)
+
+func init() {
+	tgbotapi.SetLogger(telegramLogger{})
+}
+
+// telegramLogger adapts the Telegram library's logger to slog. The library
+// uses Printf for request/response dumps and Println for polling errors.
+type telegramLogger struct{}
+
+func (telegramLogger) Printf(format string, v ...interface{}) {
+	apiLogger.Debug("telegram API call", "payload", strings.TrimSpace(fmt.Sprintf(format, v...)))
+}
+
+func (telegramLogger) Println(v ...interface{}) {
+	apiLogger.Warn(strings.TrimSpace(fmt.Sprintln(v...)))
+}
@@ -0,0 +1,122 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	updatesReceived = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "assistant_updates_received_total",
+		Help: "Telegram updates received, by type.",
+	}, []string{"type"})
+
+	updatesRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "assistant_updates_rejected_total",
+		Help: "Updates and webhook requests turned away, by reason.",
+	}, []string{"reason"})
+
+	updatesHandled = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "assistant_updates_handled_total",
+		Help: "Updates handled, by command, LLM intent and result.",
+	}, []string{"command", "intent", "result"})
+
+	updateDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "assistant_update_duration_seconds",
+		Help:    "Time to handle an update, by command.",
+		Buckets: []float64{0.1, 0.5, 1, 2, 5, 10, 20, 30, 60, 120},
+	}, []string{"command"})
+
+	updatePanics = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "assistant_update_panics_total",
+		Help: "Update handlers that panicked.",
+	})
+
+	updateQueueDepth = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "assistant_update_queue_depth",
+		Help: "Updates queued or being handled.",
+	})
+
+	offlineFallbacks = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "assistant_offline_fallbacks_total",
+		Help: "Messages handled by the built-in parser because Claude failed.",
+	})
+)
+
+// knownCommands keeps the command label to a fixed set.
+var knownCommands = map[string]bool{
+	"/start": true, "/lang": true, "/today": true, "/rsvp": true,
+	"/calendars": true, "/contacts": true, "/chat": true, "/note": true,
+	"/users": true, "/ban": true, "/unban": true, "/promote": true, "/demote": true,
+	"/stats": true, "/broadcast": true, "/reminders": true, "/loglevel": true,
+}
+
+// updateType labels an update for assistant_updates_received_total.
+func updateType(update tgbotapi.Update) string {
+	switch {
+	case update.CallbackQuery != nil:
+		return "callback"
+	case update.Message != nil && update.Message.Document != nil:
+		return "document"
+	case update.Message != nil:
+		return "message"
+	}
+	return "other"
+}
+
+// commandOf labels a message by its command, or "text" for free text.
+func commandOf(text string) string {
+	if !strings.HasPrefix(text, "/") {
+		return "text"
+	}
+	command := strings.ToLower(strings.Fields(text + " ")[0])
+	if at := strings.Index(command, "@"); at > 0 {
+		command = command[:at]
+	}
+	if !knownCommands[command] {
+		return "other"
+	}
+	return command
+}
+
+// knownIntents are the actions the LLM prompt asks for.
+var knownIntents = map[string]bool{
+	"CREATE_EVENT": true, "CHECK_TODAY": true, "CANCEL_EVENT": true,
+	"RESCHEDULE_EVENT": true, "GENERAL": true,
+}
+
+type intentKey struct{}
+
+// withIntentLabel gives the handler somewhere to note the LLM intent it
+// acted on, so it can be counted once the update is done.
+func withIntentLabel(ctx context.Context) (context.Context, *string) {
+	intent := "none"
+	return context.WithValue(ctx, intentKey{}, &intent), &intent
+}
+
+// recordIntent notes the ACTION of an LLM response for the update's metrics.
+func recordIntent(ctx context.Context, response string) {
+	intent, ok := ctx.Value(intentKey{}).(*string)
+	if !ok {
+		return
+	}
+	*intent = "unknown"
+	for _, line := range strings.Split(response, "\n") {
+		if action, found := strings.CutPrefix(line, "ACTION:"); found && knownIntents[strings.TrimSpace(action)] {
+			*intent = strings.ToLower(strings.TrimSpace(action))
+			break
+		}
+	}
+}
+
+func rejectReason(err error) string {
+	if errors.Is(err, ErrStopped) {
+		return "stopped"
+	}
+	return "queue_full"
+}
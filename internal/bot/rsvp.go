@@ -0,0 +1,57 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"virtual-assistant/internal/calendar"
+	"virtual-assistant/internal/i18n"
+	"virtual-assistant/internal/timezone"
+)
+
+const rsvpLookAheadDays = 7
+
+// getRSVPSummary lists who accepted, declined or hasn't answered for the
+// meetings we organise over the next week.
+func (tb *TelegramBot) getRSVPSummary(chatID int64) (string, error) {
+	now := time.Now()
+	userSettings := tb.settings.Get(chatID)
+	events, err := tb.calendarService.GetOrganizedEvents(userSettings.ActiveCalendars(), now, now.AddDate(0, 0, rsvpLookAheadDays))
+	if err != nil {
+		return "", fmt.Errorf("failed to get organised events: %v", err)
+	}
+
+	lang := tb.LanguageFor(chatID)
+	if len(events) == 0 {
+		return i18n.T(lang, "rsvp.none", rsvpLookAheadDays), nil
+	}
+
+	loc := timezone.Location()
+
+	var sb strings.Builder
+	sb.WriteString(i18n.T(lang, "rsvp.header"))
+	for _, event := range events {
+		byStatus := make(map[string][]string)
+		for _, attendee := range event.Attendees {
+			if attendee.Self || attendee.Resource {
+				continue
+			}
+			byStatus[attendee.ResponseStatus] = append(byStatus[attendee.ResponseStatus], calendar.AttendeeName(attendee))
+		}
+
+		when := event.Start.Date
+		if t, err := time.Parse(time.RFC3339, event.Start.DateTime); err == nil {
+			when = i18n.FormatTime(lang, t.In(loc), "Mon 02 Jan 15:04")
+		}
+
+		sb.WriteString(fmt.Sprintf("\n📅 %s (%s)\n", event.Summary, when))
+		for _, status := range []string{"accepted", "tentative", "declined", "needsAction"} {
+			if names := byStatus[status]; len(names) > 0 {
+				sb.WriteString(fmt.Sprintf("%s: %s\n", i18n.T(lang, "rsvp.status."+status), strings.Join(names, ", ")))
+			}
+		}
+	}
+
+	return sb.String(), nil
+}
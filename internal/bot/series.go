@@ -0,0 +1,190 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	gcal "google.golang.org/api/calendar/v3"
+	"virtual-assistant/internal/calendar"
+	"virtual-assistant/internal/i18n"
+	"virtual-assistant/internal/timezone"
+)
+
+// pendingChange is a cancellation or move of an existing event, parked until
+// the user confirms it since neither can be undone from the chat.
+type pendingChange struct {
+	event      *gcal.Event
+	calendarID string
+	scope      calendar.EditScope
+	cancel     bool      // Otherwise the event moves to start/end
+	start, end time.Time // New times for a move
+}
+
+// extractField returns the value of a "KEY: value" line from an LLM response.
+func extractField(response, key string) string {
+	prefix := key + ":"
+	for _, line := range strings.Split(response, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, prefix) {
+			value := strings.TrimSpace(strings.TrimPrefix(line, prefix))
+			if strings.EqualFold(value, "empty") || strings.EqualFold(value, "none") {
+				return ""
+			}
+			return value
+		}
+	}
+	return ""
+}
+
+// findTargetEvent resolves the EVENT/DATE fields to a concrete occurrence on
+// one of the chat's calendars, returning a reply for the user when it can't.
+func (tb *TelegramBot) findTargetEvent(chatID int64, response string) (*gcal.Event, string, string, error) {
+	lang := tb.LanguageFor(chatID)
+	query := extractField(response, "EVENT")
+	if query == "" {
+		return nil, "", i18n.T(lang, "series.which_event"), nil
+	}
+
+	var day time.Time
+	if dateStr := extractField(response, "DATE"); dateStr != "" {
+		loc := timezone.Location()
+		parsed, err := time.ParseInLocation("2006-01-02", dateStr, loc)
+		if err != nil {
+			return nil, "", i18n.T(lang, "series.bad_date", dateStr), nil
+		}
+		day = parsed
+	}
+
+	userSettings := tb.settings.Get(chatID)
+	event, calendarID, err := tb.calendarService.FindEvent(userSettings.ActiveCalendars(), query, day)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to find event: %v", err)
+	}
+	if event == nil {
+		return nil, "", i18n.T(lang, "series.not_found", query), nil
+	}
+
+	return event, calendarID, "", nil
+}
+
+// cancelEventFromResponse finds the event the user wants cancelled and asks
+// them to confirm before anything is deleted.
+func (tb *TelegramBot) cancelEventFromResponse(chatID int64, response string) (string, error) {
+	event, calendarID, reply, err := tb.findTargetEvent(chatID, response)
+	if event == nil {
+		return reply, err
+	}
+
+	change := &pendingChange{
+		event:      event,
+		calendarID: calendarID,
+		scope:      calendar.ParseEditScope(extractField(response, "SCOPE")),
+		cancel:     true,
+	}
+	tb.parkChange(chatID, change)
+
+	lang := tb.LanguageFor(chatID)
+	if change.series() {
+		return i18n.T(lang, "series.confirm_cancel_all", event.Summary), nil
+	}
+	return i18n.T(lang, "series.confirm_cancel", event.Summary, describeEventStart(lang, event)), nil
+}
+
+// rescheduleEventFromResponse finds the event the user wants moved and asks
+// them to confirm the new time before changing it.
+func (tb *TelegramBot) rescheduleEventFromResponse(chatID int64, response string) (string, error) {
+	event, calendarID, reply, err := tb.findTargetEvent(chatID, response)
+	if event == nil {
+		return reply, err
+	}
+
+	lang := tb.LanguageFor(chatID)
+	start, err1 := time.Parse(time.RFC3339, extractField(response, "START_TIME"))
+	end, err2 := time.Parse(time.RFC3339, extractField(response, "END_TIME"))
+	if err1 != nil || err2 != nil {
+		return i18n.T(lang, "series.when"), nil
+	}
+
+	change := &pendingChange{
+		event:      event,
+		calendarID: calendarID,
+		scope:      calendar.ParseEditScope(extractField(response, "SCOPE")),
+		start:      start,
+		end:        end,
+	}
+	tb.parkChange(chatID, change)
+
+	loc := timezone.Location()
+	newTime := fmt.Sprintf("%s-%s", start.In(loc).Format("15:04"), end.In(loc).Format("15:04"))
+	if change.series() {
+		return i18n.T(lang, "series.confirm_move_all", event.Summary, newTime), nil
+	}
+	return i18n.T(lang, "series.confirm_move", event.Summary, describeEventStart(lang, event), i18n.FormatTime(lang, start.In(loc), "Mon 02 Jan"), newTime), nil
+}
+
+// series reports whether the change applies to every occurrence.
+func (change *pendingChange) series() bool {
+	return change.scope == calendar.ScopeSeries && change.event.RecurringEventId != ""
+}
+
+func (tb *TelegramBot) parkChange(chatID int64, change *pendingChange) {
+	tb.pendingMutex.Lock()
+	tb.pendingChanges[chatID] = change
+	tb.pendingMutex.Unlock()
+}
+
+// handleChangeReply carries out or drops a parked cancellation or move.
+// handled is false when nothing is parked or the message isn't a yes or no,
+// in which case the change is dropped and the message handled normally.
+func (tb *TelegramBot) handleChangeReply(chatID int64, userMessage string) (string, bool, error) {
+	tb.pendingMutex.Lock()
+	change, exists := tb.pendingChanges[chatID]
+	delete(tb.pendingChanges, chatID)
+	tb.pendingMutex.Unlock()
+	if !exists {
+		return "", false, nil
+	}
+
+	lang := tb.LanguageFor(chatID)
+	switch strings.ToLower(strings.TrimSpace(userMessage)) {
+	case "yes", "y", "ya", "iya":
+	case "no", "n", "tidak", "/cancel":
+		return i18n.T(lang, "series.kept", change.event.Summary), true, nil
+	default:
+		return "", false, nil
+	}
+
+	event := change.event
+	if change.cancel {
+		if err := tb.calendarService.CancelEvent(change.calendarID, event, change.scope); err != nil {
+			return "", true, fmt.Errorf("failed to cancel event: %v", err)
+		}
+		if change.series() {
+			return i18n.T(lang, "series.cancelled_all", event.Summary), true, nil
+		}
+		return i18n.T(lang, "series.cancelled", event.Summary, describeEventStart(lang, event)), true, nil
+	}
+
+	if err := tb.calendarService.RescheduleEvent(change.calendarID, event, change.scope, change.start, change.end); err != nil {
+		return "", true, fmt.Errorf("failed to reschedule event: %v", err)
+	}
+
+	loc := timezone.Location()
+	newTime := fmt.Sprintf("%s-%s", change.start.In(loc).Format("15:04"), change.end.In(loc).Format("15:04"))
+	if change.series() {
+		return i18n.T(lang, "series.moved_all", event.Summary, newTime), true, nil
+	}
+	return i18n.T(lang, "series.moved", event.Summary, i18n.FormatTime(lang, change.start.In(loc), "Mon 02 Jan"), newTime), true, nil
+}
+
+func describeEventStart(lang i18n.Language, event *gcal.Event) string {
+	if event.Start == nil {
+		return i18n.T(lang, "series.unknown_date")
+	}
+	if t, err := time.Parse(time.RFC3339, event.Start.DateTime); err == nil {
+		loc := timezone.Location()
+		return i18n.FormatTime(lang, t.In(loc), "Mon 02 Jan 15:04")
+	}
+	return event.Start.Date
+}
@@ -3,67 +3,151 @@ package bot
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
-	"os"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	gcal "google.golang.org/api/calendar/v3"
 	"virtual-assistant/internal/calendar"
+	"virtual-assistant/internal/contacts"
+	"virtual-assistant/internal/i18n"
 	"virtual-assistant/internal/llm"
+	"virtual-assistant/internal/markup"
+	"virtual-assistant/internal/settings"
+	"virtual-assistant/internal/storage"
 )
 
 type TelegramBot struct {
 	bot             *tgbotapi.BotAPI
 	calendarService *calendar.CalendarService
 	claudeService   *llm.ClaudeCodeService
+	settings        *settings.Store
+	contacts        *contacts.Book
 	webhookURL      string
+	pendingEvents   map[int64]*pendingEvent // Events waiting for conflict confirmation, by chat
+	pendingNotes    map[int64]*pendingNote  // Answered invitations waiting for an optional note, by chat
+	pendingChanges  map[int64]*pendingChange // Cancellations and moves waiting for confirmation, by chat
+	pendingMutex    sync.Mutex
+	meetByDefault   atomic.Bool // Add a Meet link unless the user says it's in person
+	dispatcher      *dispatcher
+	webhook         *webhookGuard
+	polling         atomic.Bool // The polling loop is running
+	allowedChats    atomic.Pointer[map[int64]bool] // Chats that may use the bot; empty allows all
+	owners          atomic.Pointer[map[int64]bool] // Users with every admin right
+	db              *storage.DB
+	reminders       ReminderLister
 }
 
-func NewTelegramBot(token, webhookURL string, calendarService *calendar.CalendarService, claudeService *llm.ClaudeCodeService) (*TelegramBot, error) {
+// NewTelegramBot logs in to the Bot API with token. Command-line tools that
+// only call the API, such as webhook management, may pass nil services.
+func NewTelegramBot(token, webhookURL string, calendarService *calendar.CalendarService, claudeService *llm.ClaudeCodeService, settingsStore *settings.Store, contactBook *contacts.Book, db *storage.DB) (*TelegramBot, error) {
 	bot, err := tgbotapi.NewBotAPI(token)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create bot: %v", err)
 	}
 
-	bot.Debug = true
-	log.Printf("Authorized on account %s", bot.Self.UserName)
+	// Payload dumps contain message text, so they only appear at debug level
+	bot.Debug = apiLogger.Enabled(context.Background(), slog.LevelDebug)
+	logger.Info("authorized on Telegram", "account", bot.Self.UserName)
 
-	return &TelegramBot{
+	tb := &TelegramBot{
 		bot:             bot,
 		calendarService: calendarService,
 		claudeService:   claudeService,
+		settings:        settingsStore,
+		contacts:        contactBook,
 		webhookURL:      webhookURL,
-	}, nil
+		pendingEvents:   make(map[int64]*pendingEvent),
+		pendingNotes:    make(map[int64]*pendingNote),
+		pendingChanges:  make(map[int64]*pendingChange),
+		webhook:         newWebhookGuard(),
+		db:              db,
+	}
+	tb.dispatcher = newDispatcher(tb.handleUpdate, DefaultWorkers, DefaultQueueSize, DefaultUpdateTimeout)
+	return tb, nil
+}
+
+// SetMeetByDefault makes new events get a Google Meet link unless the user
+// says the meeting is in person.
+func (tb *TelegramBot) SetMeetByDefault(enabled bool) {
+	tb.meetByDefault.Store(enabled)
+}
+
+// SetConcurrency sets how many updates are handled at once, how many may wait
+// in the queue and how long each one may take. Call it before updates arrive.
+func (tb *TelegramBot) SetConcurrency(workers, queueSize int, timeout time.Duration) {
+	tb.dispatcher = newDispatcher(tb.handleUpdate, workers, queueSize, timeout)
 }
 
 func (tb *TelegramBot) SetWebhook() error {
-	webhookConfig, err := tgbotapi.NewWebhook(tb.webhookURL + "/webhook")
-	if err != nil {
+	if err := tb.webhook.ensureSecret(); err != nil {
 		return err
 	}
-	_, err = tb.bot.Request(webhookConfig)
+
+	// The library's WebhookConfig predates secret_token, so the request is
+	// built by hand
+	params := tgbotapi.Params{"url": tb.webhookURL + "/webhook"}
+	params.AddNonEmpty("secret_token", tb.webhook.secret)
+	_, err := tb.bot.MakeRequest("setWebhook", params)
 	return err
 }
 
 func (tb *TelegramBot) HandleWebhook(w http.ResponseWriter, r *http.Request) {
-	body, err := io.ReadAll(r.Body)
+	if status := tb.webhook.check(r); status != 0 {
+		updatesRejected.WithLabelValues(strings.ToLower(strings.ReplaceAll(http.StatusText(status), " ", "_"))).Inc()
+		logger.Warn("rejected webhook request", "remote_addr", r.RemoteAddr, "status", status)
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBody))
 	if err != nil {
-		log.Printf("Error reading request body: %v", err)
+		logger.Warn("failed to read webhook body", "error", err)
+		var tooLarge *http.MaxBytesError
+		updatesRejected.WithLabelValues("bad_request").Inc()
+		if errors.As(err, &tooLarge) {
+			http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+		} else {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		}
 		return
 	}
 
 	var update tgbotapi.Update
 	err = json.Unmarshal(body, &update)
 	if err != nil {
-		log.Printf("Error unmarshaling update: %v", err)
+		logger.Warn("failed to parse webhook update", "error", err)
+		updatesRejected.WithLabelValues("bad_request").Inc()
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 		return
 	}
 
-	tb.handleUpdate(update)
+	// Telegram resends updates it didn't see acknowledged; handle each once
+	if !tb.webhook.firstSeen(update.UpdateID) {
+		updatesRejected.WithLabelValues("duplicate").Inc()
+		logger.Debug("ignoring redelivered update", "update_id", update.UpdateID)
+		return
+	}
+
+	// Telegram redelivers the update if we answer with an error, so a full
+	// queue pushes back on it instead of dropping the message
+	ctx, cancel := context.WithTimeout(r.Context(), webhookQueueWait)
+	defer cancel()
+	if err := tb.dispatcher.Submit(ctx, update); err != nil {
+		logger.Warn("rejecting update", "update_id", update.UpdateID, "error", err)
+		updatesRejected.WithLabelValues(rejectReason(err)).Inc()
+		tb.webhook.forget(update.UpdateID)
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
 }
 
 func (tb *TelegramBot) StartPolling() {
@@ -71,13 +155,71 @@ func (tb *TelegramBot) StartPolling() {
 	u.Timeout = 60
 
 	updates := tb.bot.GetUpdatesChan(u)
+	tb.polling.Store(true)
+	defer tb.polling.Store(false)
 
+	// Submit blocks while the queue is full, so we simply stop fetching
 	for update := range updates {
-		tb.handleUpdate(update)
+		if err := tb.dispatcher.Submit(context.Background(), update); err != nil {
+			// Not confirmed to Telegram, so it's fetched again on the next start
+			logger.Warn("not handling update", "update_id", update.UpdateID, "error", err)
+			updatesRejected.WithLabelValues(rejectReason(err)).Inc()
+		}
 	}
 }
 
-func (tb *TelegramBot) handleUpdate(update tgbotapi.Update) {
+// StopPolling stops fetching updates from Telegram. Updates already fetched
+// are still handled.
+func (tb *TelegramBot) StopPolling() {
+	tb.bot.StopReceivingUpdates()
+}
+
+// Drain stops accepting updates and waits for the ones in hand to be
+// handled. If ctx ends first, running handlers and their claude processes
+// are cancelled.
+func (tb *TelegramBot) Drain(ctx context.Context) error {
+	return tb.dispatcher.Stop(ctx)
+}
+
+// DeleteWebhook unregisters the webhook, so Telegram holds updates until the
+// bot is back (or is run in polling mode).
+func (tb *TelegramBot) DeleteWebhook() error {
+	_, err := tb.bot.Request(tgbotapi.DeleteWebhookConfig{})
+	return err
+}
+
+// WebhookInfo returns what Telegram knows about the webhook, including
+// delivery errors.
+func (tb *TelegramBot) WebhookInfo() (tgbotapi.WebhookInfo, error) {
+	return tb.bot.GetWebhookInfo()
+}
+
+// Username returns the bot's Telegram username, without the @.
+func (tb *TelegramBot) Username() string {
+	return tb.bot.Self.UserName
+}
+
+func (tb *TelegramBot) handleUpdate(ctx context.Context, update tgbotapi.Update) {
+	start := time.Now()
+	if chatID := updateChatID(update); chatID != 0 && !tb.allowed(chatID) {
+		tb.refuse(ctx, update, chatID)
+		return
+	}
+	userID := updateUserID(update)
+	if userID != 0 && tb.roleOf(userID) == storage.RoleBanned {
+		logger.InfoContext(ctx, "ignoring update from banned user", "update_id", update.UpdateID, "user_id", userID)
+		updatesRejected.WithLabelValues("banned").Inc()
+		return
+	}
+	ctx = withSender(ctx, userID)
+
+	if update.CallbackQuery != nil {
+		tb.handleCallbackQuery(ctx, update.CallbackQuery)
+		updatesHandled.WithLabelValues("callback", "none", "ok").Inc()
+		updateDuration.WithLabelValues("callback").Observe(time.Since(start).Seconds())
+		return
+	}
+
 	if update.Message == nil {
 		return
 	}
@@ -89,52 +231,186 @@ func (tb *TelegramBot) handleUpdate(update tgbotapi.Update) {
 		firstName = update.Message.From.UserName
 	}
 
-	// Automatically save chat ID for reminders
-	tb.saveChatID(chatID, firstName)
+	// Every chat that has written to the bot gets reminders
+	tb.recordActivity(ctx, update.Message, firstName)
+	tb.rememberLanguage(ctx, chatID, update.Message.From.LanguageCode)
 
-	log.Printf("Received message from %d (%s): %s", chatID, firstName, userMessage)
+	// The text itself only goes into debug logs
+	command := commandOf(userMessage)
+	logger.InfoContext(ctx, "received message", "update_id", update.UpdateID, "chat_id", chatID, "name", firstName, "command", command, "length", len(userMessage))
+	logger.DebugContext(ctx, "message text", "update_id", update.UpdateID, "chat_id", chatID, "text", userMessage)
 
-	response, err := tb.processMessage(userMessage)
+	ctx, intent := withIntentLabel(ctx)
+	lang := tb.LanguageFor(chatID)
+	ctx = llm.WithRequester(ctx, strconv.FormatInt(chatID, 10), func(position int) {
+		tb.sendHTML(chatID, markup.Escape(i18n.T(lang, "llm.queued", position)), nil)
+	})
+
+	var response *reply
+	var err error
+	if document := update.Message.Document; document != nil && strings.HasPrefix(strings.ToLower(update.Message.Caption), "/contacts import") {
+		command = "/contacts"
+		response, err = textReply(tb.handleContactsImport(ctx, chatID, document))
+	} else {
+		response, err = tb.processMessage(ctx, chatID, userMessage)
+	}
+	result := "ok"
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to process message", "chat_id", chatID, "error", err)
+		response = &reply{text: markup.Escape(i18n.T(lang, "error.generic"))}
+		result = "error"
+	}
+	updatesHandled.WithLabelValues(command, *intent, result).Inc()
+	updateDuration.WithLabelValues(command).Observe(time.Since(start).Seconds())
+
+	if _, err := tb.sendHTML(chatID, response.text, response.keyboard); err != nil {
+		logger.ErrorContext(ctx, "failed to send reply", "chat_id", chatID, "error", err)
+	}
+	tb.recordHistory(ctx, chatID, userMessage, response.text)
+}
+
+// Ask runs text through the same pipeline as a message from chatID and
+// returns the reply instead of sending it, for the REST API. Nothing is sent
+// to the chat. It waits its turn behind the chat's Telegram updates, and a
+// reply asking for confirmation is answered by the chat's next message,
+// whether it comes through Ask or Telegram.
+func (tb *TelegramBot) Ask(ctx context.Context, chatID int64, text string) (markup.HTML, error) {
+	var response *reply
+	var err error
+	doErr := tb.dispatcher.Do(ctx, chatID, func(ctx context.Context) {
+		ctx = llm.WithRequester(ctx, strconv.FormatInt(chatID, 10), nil)
+		ctx, _ = withIntentLabel(ctx)
+
+		response, err = tb.processMessage(ctx, chatID, text)
+		if err == nil {
+			tb.recordHistory(ctx, chatID, text, response.text)
+		}
+	})
+	if doErr != nil {
+		return "", doErr
+	}
 	if err != nil {
-		log.Printf("Error processing message: %v", err)
-		response = "Sorry, I encountered an error processing your request."
+		return "", err
 	}
+	return response.text, nil
+}
 
-	msg := tgbotapi.NewMessage(chatID, response)
-	tb.bot.Send(msg)
+// reply is a response to the user with optional inline buttons.
+type reply struct {
+	text     markup.HTML
+	keyboard *tgbotapi.InlineKeyboardMarkup
 }
 
-func (tb *TelegramBot) processMessage(userMessage string) (string, error) {
-	ctx := context.Background()
+// textReply wraps a plain-text handler result so it can be returned directly
+// from processMessage.
+func textReply(text string, err error) (*reply, error) {
+	if err != nil {
+		return nil, err
+	}
+	return &reply{text: markup.Escape(text)}, nil
+}
+
+// markdownReply is textReply for LLM output, whose Markdown is rendered
+// rather than shown as literal asterisks.
+func markdownReply(text string, err error) (*reply, error) {
+	if err != nil {
+		return nil, err
+	}
+	return &reply{text: markup.FromMarkdown(text)}, nil
+}
+
+// sendHTML sends an HTML message, split into as many messages as Telegram's
+// length limit needs. Buttons go on the last part.
+func (tb *TelegramBot) sendHTML(chatID int64, text markup.HTML, keyboard *tgbotapi.InlineKeyboardMarkup) (tgbotapi.Message, error) {
+	chunks := markup.Split(text, markup.MaxMessageLength)
+
+	var sent tgbotapi.Message
+	for i, chunk := range chunks {
+		msg := tgbotapi.NewMessage(chatID, string(chunk))
+		msg.ParseMode = tgbotapi.ModeHTML
+		if keyboard != nil && i == len(chunks)-1 {
+			msg.ReplyMarkup = *keyboard
+		}
+
+		var err error
+		sent, err = tb.bot.Send(msg)
+		if err != nil {
+			return sent, err
+		}
+	}
+	return sent, nil
+}
+
+func (tb *TelegramBot) processMessage(ctx context.Context, chatID int64, userMessage string) (*reply, error) {
+	lang := tb.LanguageFor(chatID)
+
+	// Pending confirmations and invitation notes take priority over everything else
+	if response, handled, err := tb.handlePendingReply(ctx, chatID, userMessage); handled {
+		return textReply(response, err)
+	}
+
+	if response, handled, err := tb.handleChangeReply(chatID, userMessage); handled {
+		return textReply(response, err)
+	}
+
+	if response, handled, err := tb.handleNoteReply(chatID, userMessage); handled {
+		return textReply(response, err)
+	}
+
+	if command := commandOf(userMessage); adminCommands[command] != "" {
+		return textReply(tb.handleAdminCommand(ctx, chatID, command, commandArgs(userMessage)))
+	}
 
 	if strings.HasPrefix(strings.ToLower(userMessage), "/start") {
-		return "Hello! I'm your virtual assistant. I can help you:\n" +
-			"• Create calendar events\n" +
-			"• Check today's meetings (/today)\n" +
-			"• Send reminders for upcoming meetings\n" +
-			"• General chat (/chat <message>)\n\n" +
-			"Just tell me what you'd like to do!", nil
+		return textReply(i18n.T(lang, "start.help"), nil)
+	}
+
+	if strings.HasPrefix(strings.ToLower(userMessage), "/lang") {
+		return textReply(tb.handleLangCommand(chatID, strings.TrimSpace(userMessage[len("/lang"):])))
 	}
 
 	if strings.HasPrefix(strings.ToLower(userMessage), "/today") {
-		return tb.getTodayEvents()
+		return tb.getTodayEvents(chatID)
+	}
+
+	if strings.HasPrefix(strings.ToLower(userMessage), "/rsvp") {
+		return textReply(tb.getRSVPSummary(chatID))
+	}
+
+	if strings.HasPrefix(strings.ToLower(userMessage), "/calendars") {
+		return textReply(tb.handleCalendarsCommand(chatID, strings.TrimSpace(userMessage[len("/calendars"):])))
+	}
+
+	if strings.HasPrefix(strings.ToLower(userMessage), "/contacts") {
+		return textReply(tb.handleContactsCommand(ctx, lang, strings.TrimSpace(userMessage[len("/contacts"):])))
 	}
 
 	if strings.HasPrefix(strings.ToLower(userMessage), "/chat ") {
 		// Extract the message after "/chat "
 		chatMessage := strings.TrimSpace(userMessage[6:])
-		return tb.handleGeneralChat(ctx, chatMessage)
+		return markdownReply(tb.handleGeneralChat(ctx, lang, chatMessage))
 	}
 
-	claudeResponse, err := tb.claudeService.ProcessCalendarCommand(ctx, userMessage)
+	claudeResponse, err := tb.claudeService.ProcessCalendarCommand(ctx, userMessage, lang.Name())
 	if err != nil {
-		return "", fmt.Errorf("failed to get Claude response: %v", err)
+		// Simple requests still work while the model is unavailable
+		if fallback, ok := offlineIntent(lang, userMessage); ok {
+			logger.WarnContext(ctx, "Claude unavailable, handling the message without it", "error", err)
+			offlineFallbacks.Inc()
+			recordIntent(ctx, fallback)
+			return tb.handleClaudeResponse(ctx, chatID, userMessage, fallback)
+		}
+		if errors.Is(err, llm.ErrBusy) {
+			return textReply(i18n.T(lang, "llm.busy"), nil)
+		}
+		return nil, fmt.Errorf("failed to get Claude response: %v", err)
 	}
 
-	return tb.handleClaudeResponse(claudeResponse)
+	recordIntent(ctx, claudeResponse)
+	return tb.handleClaudeResponse(ctx, chatID, userMessage, claudeResponse)
 }
 
-func (tb *TelegramBot) handleClaudeResponse(claudeResponse string) (string, error) {
+func (tb *TelegramBot) handleClaudeResponse(ctx context.Context, chatID int64, userMessage, claudeResponse string) (*reply, error) {
 	lines := strings.Split(claudeResponse, "\n")
 	
 	for _, line := range lines {
@@ -143,21 +419,26 @@ func (tb *TelegramBot) handleClaudeResponse(claudeResponse string) (string, erro
 			
 			switch action {
 			case "CREATE_EVENT":
-				return tb.createEventFromResponse(claudeResponse)
+				return textReply(tb.createEventFromResponse(ctx, chatID, userMessage, claudeResponse))
 			case "CHECK_TODAY":
-				return tb.getTodayEvents()
+				return tb.getTodayEvents(chatID)
+			case "CANCEL_EVENT":
+				return textReply(tb.cancelEventFromResponse(chatID, claudeResponse))
+			case "RESCHEDULE_EVENT":
+				return textReply(tb.rescheduleEventFromResponse(chatID, claudeResponse))
 			case "GENERAL":
-				return tb.getGeneralResponse(claudeResponse)
+				return markdownReply(tb.getGeneralResponse(claudeResponse))
 			}
 		}
 	}
 
-	return claudeResponse, nil
+	return markdownReply(claudeResponse, nil)
 }
 
-func (tb *TelegramBot) createEventFromResponse(response string) (string, error) {
+func (tb *TelegramBot) createEventFromResponse(ctx context.Context, chatID int64, userMessage, response string) (string, error) {
+	lang := tb.LanguageFor(chatID)
 	lines := strings.Split(response, "\n")
-	var title, description, startTime, endTime, attendeesStr string
+	var title, description, startTime, endTime, attendeesStr, recurrenceStr, allDayStr, calendarName, onlineStr string
 
 	for _, line := range lines {
 		line = strings.TrimSpace(line)
@@ -171,54 +452,134 @@ func (tb *TelegramBot) createEventFromResponse(response string) (string, error)
 			endTime = strings.TrimSpace(strings.TrimPrefix(line, "END_TIME:"))
 		} else if strings.HasPrefix(line, "ATTENDEES:") {
 			attendeesStr = strings.TrimSpace(strings.TrimPrefix(line, "ATTENDEES:"))
+		} else if strings.HasPrefix(line, "RECURRENCE:") {
+			recurrenceStr = strings.TrimSpace(strings.TrimPrefix(line, "RECURRENCE:"))
+		} else if strings.HasPrefix(line, "ALL_DAY:") {
+			allDayStr = strings.TrimSpace(strings.TrimPrefix(line, "ALL_DAY:"))
+		} else if strings.HasPrefix(line, "CALENDAR:") {
+			calendarName = strings.TrimSpace(strings.TrimPrefix(line, "CALENDAR:"))
+		} else if strings.HasPrefix(line, "ONLINE:") {
+			onlineStr = strings.TrimSpace(strings.TrimPrefix(line, "ONLINE:"))
 		}
 	}
 
 	if title == "" || startTime == "" || endTime == "" {
-		return "I need more information to create the event. Please provide a title, start time, and end time.", nil
+		return i18n.T(lang, "event.need_info"), nil
 	}
 
-	// Parse attendees
-	var attendees []string
+	recurrence, err := calendar.ParseRecurrence(recurrenceStr)
+	if err != nil {
+		return i18n.T(lang, "event.bad_recurrence", err), nil
+	}
+
+	event := &pendingEvent{
+		title:       title,
+		description: description,
+		startTime:   startTime,
+		endTime:     endTime,
+		recurrence:  recurrence,
+		allDay:      strings.EqualFold(allDayStr, "YES"),
+	}
+
+	// Attach a Meet link when asked for, or by default unless it's in person
+	switch strings.ToUpper(onlineStr) {
+	case "YES":
+		event.conference = true
+	case "NO":
+		event.conference = false
+	default:
+		event.conference = tb.meetByDefault.Load() && !event.allDay
+	}
+
+	event.calendarID, err = tb.resolveTargetCalendar(chatID, calendarName, title, description)
+	if err != nil {
+		return "", err
+	}
+
+	if event.allDay {
+		if err := normaliseAllDayDates(event); err != nil {
+			return i18n.T(lang, "event.bad_dates", err), nil
+		}
+	}
+
+	// Don't take the model's word for times the user spelled out
+	correctEventTimes(ctx, userMessage, event)
+
+	// Names and groups go through the contact book
 	if attendeesStr != "" && attendeesStr != "empty" {
-		// Split by comma and clean up emails
-		for _, email := range strings.Split(attendeesStr, ",") {
-			email = strings.TrimSpace(email)
-			if email != "" {
-				attendees = append(attendees, email)
-			}
+		if unknown := tb.resolveAttendees(attendeesStr, event); len(unknown) > 0 {
+			return i18n.T(lang, "attendees.unknown", strings.Join(unknown, ", ")), nil
+		}
+		if len(event.unresolved) > 0 {
+			tb.pendingMutex.Lock()
+			tb.pendingEvents[chatID] = event
+			tb.pendingMutex.Unlock()
+			return formatAttendeeQuestion(lang, event.unresolved[0]), nil
 		}
 	}
 
-	// Create event with attendees
-	err := tb.calendarService.CreateEventWithAttendees(title, description, startTime, endTime, attendees)
-	if err != nil {
-		return "", fmt.Errorf("failed to create event: %v", err)
+	return tb.finishEvent(ctx, chatID, event)
+}
+
+// finishEvent runs the last checks on a fully resolved event and creates it.
+func (tb *TelegramBot) finishEvent(ctx context.Context, chatID int64, event *pendingEvent) (string, error) {
+	if event.allDay {
+		// All-day entries (time off, holidays) don't get a conflict check
+		return tb.commitEvent(chatID, event)
 	}
 
-	// Build response message
-	responseMsg := fmt.Sprintf("✅ Event created successfully!\n\nTitle: %s\nDescription: %s\nStart: %s\nEnd: %s", 
-		title, description, startTime, endTime)
-	
-	if len(attendees) > 0 {
-		responseMsg += fmt.Sprintf("\nAttendees: %s", strings.Join(attendees, ", "))
+	// Warn about overlaps before committing anything to the calendar
+	if warning, err := tb.checkEventConflicts(chatID, event); err != nil {
+		logger.WarnContext(ctx, "conflict check failed, creating event anyway", "error", err)
+	} else if warning != "" {
+		return warning, nil
 	}
 
-	return responseMsg, nil
+	return tb.commitEvent(chatID, event)
 }
 
-func (tb *TelegramBot) getTodayEvents() (string, error) {
-	events, err := tb.calendarService.GetTodayEvents()
+func (tb *TelegramBot) getTodayEvents(chatID int64) (*reply, error) {
+	lang := tb.LanguageFor(chatID)
+	userSettings := tb.settings.Get(chatID)
+	events, err := tb.calendarService.GetTodayEventsFrom(userSettings.ActiveCalendars())
 	if err != nil {
-		return "", fmt.Errorf("failed to get today's events: %v", err)
+		return nil, fmt.Errorf("failed to get today's events: %v", err)
 	}
 
 	if len(events) == 0 {
-		return "📅 No meetings scheduled for today!", nil
+		return textReply(i18n.T(lang, "today.none"), nil)
+	}
+
+	var allDayEvents, timedEvents []*gcal.Event
+	for _, event := range events {
+		if calendar.IsAllDay(event) {
+			allDayEvents = append(allDayEvents, event)
+		} else {
+			timedEvents = append(timedEvents, event)
+		}
 	}
 
-	response := "📅 Today's meetings:\n\n"
-	for i, event := range events {
+	var response markup.HTML
+	if len(allDayEvents) > 0 {
+		response += markup.Escape(i18n.T(lang, "today.all_day"))
+		for _, event := range allDayEvents {
+			response += "• " + markup.Bold(event.Summary)
+			if day, total := calendar.AllDaySpan(event, time.Now()); total > 1 && day > 0 {
+				response += markup.Escape(i18n.T(lang, "today.day_of", day, total))
+			}
+			response += "\n"
+		}
+		response += "\n"
+	}
+
+	if len(timedEvents) == 0 {
+		return &reply{text: response + markup.Escape(i18n.T(lang, "today.none"))}, nil
+	}
+
+	var joinButtons [][]tgbotapi.InlineKeyboardButton
+
+	response += markup.Escape(i18n.T(lang, "today.header"))
+	for i, event := range timedEvents {
 		startTime := ""
 		if event.Start.DateTime != "" {
 			if t, err := time.Parse(time.RFC3339, event.Start.DateTime); err == nil {
@@ -226,17 +587,29 @@ func (tb *TelegramBot) getTodayEvents() (string, error) {
 			}
 		}
 		
-		response += fmt.Sprintf("%d. %s", i+1, event.Summary)
+		response += markup.Sprintf("%d. %s", i+1, markup.Bold(event.Summary))
 		if startTime != "" {
-			response += fmt.Sprintf(" at %s", startTime)
+			response += markup.Escape(i18n.T(lang, "today.at", startTime))
 		}
 		if event.Description != "" {
-			response += fmt.Sprintf("\n   📝 %s", event.Description)
+			response += markup.Sprintf("\n   📝 %s", event.Description)
+		}
+		if joinURL := calendar.JoinURL(event); joinURL != "" {
+			response += markup.Escape(i18n.T(lang, "today.online"))
+			joinButtons = append(joinButtons, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonURL(i18n.T(lang, "button.join_numbered", i+1, event.Summary), joinURL),
+			))
 		}
 		response += "\n\n"
 	}
 
-	return response, nil
+	agenda := &reply{text: response}
+	if len(joinButtons) > 0 {
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(joinButtons...)
+		agenda.keyboard = &keyboard
+	}
+
+	return agenda, nil
 }
 
 func (tb *TelegramBot) getGeneralResponse(claudeResponse string) (string, error) {
@@ -251,88 +624,122 @@ func (tb *TelegramBot) getGeneralResponse(claudeResponse string) (string, error)
 	return claudeResponse, nil
 }
 
-func (tb *TelegramBot) SendReminder(chatID int64, message string) error {
-	preview := message
-	if len(message) > 50 {
-		preview = message[:50] + "..."
+// SendNotification pushes a plain message that isn't a meeting reminder, such
+// as an RSVP update.
+func (tb *TelegramBot) SendNotification(chatID int64, message string) error {
+	_, err := tb.sendHTML(chatID, markup.Escape(message), nil)
+	return err
+}
+
+func (tb *TelegramBot) SendReminder(chatID int64, message markup.HTML) error {
+	return tb.SendReminderWithJoin(chatID, message, "")
+}
+
+// SendReminderWithJoin sends a reminder with a one-tap "Join" button when the
+// meeting has a conference link.
+func (tb *TelegramBot) SendReminderWithJoin(chatID int64, message markup.HTML, joinURL string) error {
+	preview := []rune(string(message))
+	if len(preview) > 50 {
+		preview = append(preview[:50], []rune("...")...)
 	}
-	log.Printf("📤 Sending reminder to chat %d: %s", chatID, preview)
+	logger.Debug("sending reminder", "chat_id", chatID, "preview", string(preview))
 	
-	msg := tgbotapi.NewMessage(chatID, "🔔 Meeting Reminder:\n"+message)
+	lang := tb.LanguageFor(chatID)
+	var keyboard *tgbotapi.InlineKeyboardMarkup
+	if joinURL != "" {
+		joinKeyboard := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonURL(i18n.T(lang, "button.join"), joinURL),
+		))
+		keyboard = &joinKeyboard
+	}
 	
-	response, err := tb.bot.Send(msg)
+	response, err := tb.sendHTML(chatID, "🔔 "+markup.Bold(i18n.T(lang, "reminder.heading"))+"\n\n"+message, keyboard)
 	if err != nil {
-		log.Printf("❌ Telegram API error: %v", err)
+		logger.Error("failed to send reminder", "chat_id", chatID, "error", err)
 		return err
 	}
 	
-	log.Printf("✅ Telegram message sent successfully. Message ID: %d", response.MessageID)
+	logger.Debug("reminder sent", "chat_id", chatID, "message_id", response.MessageID)
 	return nil
 }
 
-func (tb *TelegramBot) handleGeneralChat(ctx context.Context, message string) (string, error) {
+func (tb *TelegramBot) handleGeneralChat(ctx context.Context, lang i18n.Language, message string) (string, error) {
 	// Use Claude Code for general conversation
-	response, err := tb.claudeService.GeneralChat(ctx, message)
+	response, err := tb.claudeService.GeneralChat(ctx, message, lang.Name())
+	if errors.Is(err, llm.ErrBusy) {
+		return i18n.T(lang, "llm.busy"), nil
+	}
 	if err != nil {
 		return "", fmt.Errorf("failed to get chat response: %v", err)
 	}
 	return "💬 " + response, nil
 }
 
-// Chat ID storage management
-const chatIDsFile = "chat_ids.json"
+func (tb *TelegramBot) recordActivity(ctx context.Context, message *tgbotapi.Message, firstName string) {
+	chat := storage.Chat{ID: message.Chat.ID, Type: message.Chat.Type, Title: message.Chat.Title}
+	if chat.Title == "" {
+		chat.Title = firstName
+	}
+	var user *storage.User
+	if from := message.From; from != nil {
+		user = &storage.User{
+			ID:           from.ID,
+			FirstName:    from.FirstName,
+			LastName:     from.LastName,
+			Username:     from.UserName,
+			LanguageCode: from.LanguageCode,
+		}
+	}
 
-type ChatIDStore struct {
-	ChatIDs map[int64]string `json:"chat_ids"` // chatID -> user first name
+	if err := tb.db.RecordActivity(chat, user); err != nil {
+		logger.ErrorContext(ctx, "failed to save chat", "chat_id", chat.ID, "error", err)
+	}
 }
 
-func (tb *TelegramBot) saveChatID(chatID int64, firstName string) {
-	store := tb.loadChatIDs()
-	store.ChatIDs[chatID] = firstName
-	
-	data, err := json.MarshalIndent(store, "", "  ")
-	if err != nil {
-		log.Printf("Error marshaling chat IDs: %v", err)
+// recordHistory keeps the exchange so it can be looked back on later.
+func (tb *TelegramBot) recordHistory(ctx context.Context, chatID int64, text string, response markup.HTML) {
+	if text == "" {
 		return
 	}
-	
-	err = os.WriteFile(chatIDsFile, data, 0644)
+	now := time.Now()
+	err := tb.db.AppendHistory(chatID,
+		storage.Message{Role: "user", Text: text, At: now},
+		storage.Message{Role: "assistant", Text: string(response), At: now},
+	)
 	if err != nil {
-		log.Printf("Error saving chat IDs: %v", err)
-	} else {
-		log.Printf("Saved chat ID %d for user %s", chatID, firstName)
+		logger.ErrorContext(ctx, "failed to save history", "chat_id", chatID, "error", err)
 	}
 }
 
-func (tb *TelegramBot) loadChatIDs() *ChatIDStore {
-	store := &ChatIDStore{
-		ChatIDs: make(map[int64]string),
-	}
-	
-	data, err := os.ReadFile(chatIDsFile)
+// GetAllChatIDs returns every chat that has written to the bot and may still
+// hear from it: chats dropped from the allowlist and banned users are left
+// out of reminders, notices and invitations.
+func (tb *TelegramBot) GetAllChatIDs() []int64 {
+	chatIDs, err := tb.db.ChatIDs()
 	if err != nil {
-		// File doesn't exist or can't be read, return empty store
-		return store
+		logger.Error("failed to load chats", "error", err)
 	}
-	
-	err = json.Unmarshal(data, store)
-	if err != nil {
-		log.Printf("Error unmarshaling chat IDs: %v", err)
-		return &ChatIDStore{ChatIDs: make(map[int64]string)}
+
+	recipients := chatIDs[:0]
+	for _, chatID := range chatIDs {
+		if tb.allowed(chatID) && tb.roleOf(chatID) != storage.RoleBanned {
+			recipients = append(recipients, chatID)
+		}
 	}
-	
-	return store
+	return recipients
 }
 
-func (tb *TelegramBot) GetAllChatIDs() []int64 {
-	store := tb.loadChatIDs()
-	var chatIDs []int64
-	
-	for chatID := range store.ChatIDs {
-		chatIDs = append(chatIDs, chatID)
+// OwnerChatIDs returns the private chats of the bot's owners, for what only
+// they should see or act on: invitation cards and attendee responses.
+func (tb *TelegramBot) OwnerChatIDs() []int64 {
+	var owners []int64
+	for _, chatID := range tb.GetAllChatIDs() {
+		// Private chats share their ID with the user; groups are negative
+		if tb.roleOf(chatID) == storage.RoleOwner {
+			owners = append(owners, chatID)
+		}
 	}
-	
-	return chatIDs
+	return owners
 }
 
 func (tb *TelegramBot) GetChatID() int64 {
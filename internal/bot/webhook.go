@@ -0,0 +1,162 @@
+package bot
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const (
+	// Updates are a few KB at most; anything bigger isn't from Telegram
+	maxWebhookBody = 1 << 20
+
+	secretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+	// How many recent update IDs are remembered to spot redeliveries
+	seenUpdatesSize = 1000
+)
+
+// telegramRanges are the networks Telegram sends webhooks from, see
+// https://core.telegram.org/bots/webhooks.
+var telegramRanges = []string{"149.154.160.0/20", "91.108.4.0/22"}
+
+// webhookGuard decides which webhook requests are genuine Telegram updates.
+type webhookGuard struct {
+	secret     string
+	allowed    []*net.IPNet // Empty means any address
+	trustProxy bool         // Take the client address from X-Forwarded-For
+
+	mu    sync.Mutex
+	seen  map[int]bool
+	order []int // Update IDs in seen, oldest first
+}
+
+func newWebhookGuard() *webhookGuard {
+	return &webhookGuard{seen: make(map[int]bool)}
+}
+
+// SetWebhookSecurity sets the secret Telegram must send with every update and
+// the addresses updates may come from. An empty secret gets a random one;
+// allowedIPs is a comma-separated list of CIDRs, where "telegram" stands for
+// Telegram's own ranges, or empty to accept any address. Set trustProxy when
+// running behind a reverse proxy such as ngrok, so the address is read from
+// X-Forwarded-For. Call it before SetWebhook.
+func (tb *TelegramBot) SetWebhookSecurity(secret, allowedIPs string, trustProxy bool) error {
+	var allowed []*net.IPNet
+	for _, cidr := range strings.Split(allowedIPs, ",") {
+		cidr = strings.TrimSpace(cidr)
+		cidrs := []string{cidr}
+		if strings.EqualFold(cidr, "telegram") {
+			cidrs = telegramRanges
+		}
+		for _, c := range cidrs {
+			if c == "" {
+				continue
+			}
+			_, network, err := net.ParseCIDR(c)
+			if err != nil {
+				return fmt.Errorf("invalid webhook IP range %q: %v", c, err)
+			}
+			allowed = append(allowed, network)
+		}
+	}
+
+	tb.webhook.secret = secret
+	tb.webhook.allowed = allowed
+	tb.webhook.trustProxy = trustProxy
+	return nil
+}
+
+// ensureSecret makes sure there is a secret to register with Telegram.
+func (g *webhookGuard) ensureSecret() error {
+	if g.secret != "" {
+		return nil
+	}
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Errorf("failed to generate webhook secret: %v", err)
+	}
+	g.secret = hex.EncodeToString(buf)
+	return nil
+}
+
+// check returns the HTTP status to reject r with, or 0 if it may go on.
+func (g *webhookGuard) check(r *http.Request) int {
+	if r.Method != http.MethodPost {
+		return http.StatusMethodNotAllowed
+	}
+	if !g.allowedAddress(r) {
+		return http.StatusForbidden
+	}
+	token := r.Header.Get(secretTokenHeader)
+	if g.secret == "" || subtle.ConstantTimeCompare([]byte(token), []byte(g.secret)) != 1 {
+		return http.StatusUnauthorized
+	}
+	return 0
+}
+
+func (g *webhookGuard) allowedAddress(r *http.Request) bool {
+	if len(g.allowed) == 0 {
+		return true
+	}
+
+	address, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		address = r.RemoteAddr
+	}
+	if forwarded := r.Header.Get("X-Forwarded-For"); g.trustProxy && forwarded != "" {
+		// The proxy appends the address it saw, so the last entry is the one to trust
+		hops := strings.Split(forwarded, ",")
+		address = strings.TrimSpace(hops[len(hops)-1])
+	}
+
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+	for _, network := range g.allowed {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// firstSeen records the update and reports whether it's new, in one step so
+// two concurrent deliveries of the same update can't both get through. The
+// oldest IDs are forgotten once full.
+func (g *webhookGuard) firstSeen(updateID int) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.seen[updateID] {
+		return false
+	}
+	g.seen[updateID] = true
+	g.order = append(g.order, updateID)
+	if len(g.order) > seenUpdatesSize {
+		delete(g.seen, g.order[0])
+		g.order = g.order[1:]
+	}
+	return true
+}
+
+// forget drops an update that couldn't be queued, so Telegram's redelivery
+// is accepted.
+func (g *webhookGuard) forget(updateID int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	delete(g.seen, updateID)
+	for i, id := range g.order {
+		if id == updateID {
+			g.order = append(g.order[:i], g.order[i+1:]...)
+			break
+		}
+	}
+}
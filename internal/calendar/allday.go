@@ -0,0 +1,78 @@
+package calendar
+
+import (
+	"fmt"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+	"virtual-assistant/internal/timezone"
+)
+
+// IsAllDay reports whether the event uses dates instead of date-times, which
+// is how Google represents holidays, OOO blocks and multi-day conferences.
+func IsAllDay(event *calendar.Event) bool {
+	return event.Start != nil && event.Start.DateTime == "" && event.Start.Date != ""
+}
+
+// AllDayRange returns the first day and the exclusive end day of an all-day
+// event, both at local midnight.
+func AllDayRange(event *calendar.Event) (time.Time, time.Time, error) {
+	loc := timezone.Location()
+
+	start, err := time.ParseInLocation("2006-01-02", event.Start.Date, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid start date %q: %v", event.Start.Date, err)
+	}
+
+	end := start.AddDate(0, 0, 1)
+	if event.End != nil && event.End.Date != "" {
+		end, err = time.ParseInLocation("2006-01-02", event.End.Date, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid end date %q: %v", event.End.Date, err)
+		}
+	}
+
+	return start, end, nil
+}
+
+// AllDaySpan returns how many days an all-day event lasts and which of those
+// days the given moment falls on (1-based, 0 when outside the event).
+func AllDaySpan(event *calendar.Event, on time.Time) (day int, total int) {
+	start, end, err := AllDayRange(event)
+	if err != nil {
+		return 0, 1
+	}
+
+	total = int(end.Sub(start).Hours()+12) / 24 // +12h absorbs DST shifts
+	if total < 1 {
+		total = 1
+	}
+
+	on = on.In(start.Location())
+	if on.Before(start) || !on.Before(end) {
+		return 0, total
+	}
+	return int(on.Sub(start).Hours())/24 + 1, total
+}
+
+// GetAllDayEventsStarting returns all-day events on the given calendars whose
+// first day falls on the given date.
+func (cs *CalendarService) GetAllDayEventsStarting(calendarIDs []string, day time.Time) ([]*calendar.Event, error) {
+	loc := timezone.Location()
+	day = day.In(loc)
+	startOfDay := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc)
+
+	events, err := cs.listEvents(calendarIDs, startOfDay, startOfDay.Add(24*time.Hour))
+	if err != nil {
+		return nil, err
+	}
+
+	var allDay []*calendar.Event
+	for _, event := range events {
+		if IsAllDay(event) && event.Start.Date == startOfDay.Format("2006-01-02") {
+			allDay = append(allDay, event)
+		}
+	}
+
+	return allDay, nil
+}
@@ -0,0 +1,131 @@
+package calendar
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// RecentAttendees returns the distinct people we met with over the last
+// given number of days, skipping ourselves and meeting rooms.
+func (cs *CalendarService) RecentAttendees(calendarIDs []string, days int) ([]*calendar.EventAttendee, error) {
+	now := time.Now()
+	events, err := cs.listEvents(calendarIDs, now.AddDate(0, 0, -days), now)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var attendees []*calendar.EventAttendee
+	for _, event := range events {
+		for _, attendee := range event.Attendees {
+			email := strings.ToLower(attendee.Email)
+			if attendee.Self || attendee.Resource || email == "" || seen[email] {
+				continue
+			}
+			seen[email] = true
+			attendees = append(attendees, attendee)
+		}
+	}
+
+	return attendees, nil
+}
+
+// GetOrganizedEvents returns events between from and to that we organise and
+// that have at least one other attendee, i.e. the ones with RSVPs to follow.
+func (cs *CalendarService) GetOrganizedEvents(calendarIDs []string, from, to time.Time) ([]*calendar.Event, error) {
+	events, err := cs.listEvents(calendarIDs, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var organized []*calendar.Event
+	for _, event := range events {
+		if event.Organizer == nil || !event.Organizer.Self {
+			continue
+		}
+		for _, attendee := range event.Attendees {
+			if !attendee.Self && !attendee.Resource {
+				organized = append(organized, event)
+				break
+			}
+		}
+	}
+
+	return organized, nil
+}
+
+// AttendeeName prefers the attendee's display name over their email.
+func AttendeeName(attendee *calendar.EventAttendee) string {
+	if attendee.DisplayName != "" {
+		return attendee.DisplayName
+	}
+	return attendee.Email
+}
+
+// GetPendingInvitations returns events organised by someone else where we
+// are an attendee who hasn't answered yet.
+func (cs *CalendarService) GetPendingInvitations(calendarIDs []string, from, to time.Time) ([]*calendar.Event, error) {
+	events, err := cs.listEvents(calendarIDs, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []*calendar.Event
+	for _, event := range events {
+		if event.Organizer != nil && event.Organizer.Self {
+			continue
+		}
+		if self := selfAttendee(event); self != nil && self.ResponseStatus == "needsAction" {
+			pending = append(pending, event)
+		}
+	}
+
+	return pending, nil
+}
+
+// RespondToInvitation sets our own attendee status ("accepted", "tentative"
+// or "declined") and optional note, and lets the organiser know. An empty
+// status keeps the current answer and only updates the note.
+func (cs *CalendarService) RespondToInvitation(calendarID, eventID, status, comment string) (*calendar.Event, error) {
+	start := time.Now()
+	event, err := cs.service.Events.Get(calendarID, eventID).Do()
+	observe("events.get", start, err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load event: %v", err)
+	}
+
+	self := selfAttendee(event)
+	if self == nil {
+		return nil, fmt.Errorf("you are not an attendee of this event")
+	}
+	if status != "" {
+		self.ResponseStatus = status
+	}
+	if comment != "" {
+		self.Comment = comment
+	}
+
+	// Attendees can only be patched as a whole list
+	start = time.Now()
+	updated, err := cs.service.Events.Patch(calendarID, eventID, &calendar.Event{
+		Attendees: event.Attendees,
+	}).SendUpdates("all").Do()
+	observe("events.patch", start, err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update response: %v", err)
+	}
+
+	return updated, nil
+}
+
+func selfAttendee(event *calendar.Event) *calendar.EventAttendee {
+	for _, attendee := range event.Attendees {
+		if attendee.Self {
+			return attendee
+		}
+	}
+	return nil
+}
@@ -3,9 +3,9 @@ package calendar
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
-	"log"
 	"net/http"
 	"os"
 	"time"
@@ -14,15 +14,62 @@ import (
 	"golang.org/x/oauth2/google"
 	"google.golang.org/api/calendar/v3"
 	"google.golang.org/api/option"
+	"virtual-assistant/internal/crypt"
+	"virtual-assistant/internal/logging"
+	"virtual-assistant/internal/timezone"
 )
 
+var logger = logging.For("calendar")
+
 type CalendarService struct {
 	service *calendar.Service
 }
 
-func NewCalendarService(credentialsPath string) (*CalendarService, error) {
-	ctx := context.Background()
+// NewCalendarService connects to Google Calendar with the OAuth client in
+// credentialsPath and the token cached in tokenPath. Without a cached token
+// it runs the browser authorization flow, with its callback on oauthPort.
+func NewCalendarService(credentialsPath, tokenPath string, oauthPort int) (*CalendarService, error) {
+	config, err := oauthConfig(credentialsPath)
+	if err != nil {
+		return nil, err
+	}
 	
+	return newService(getClient(config, tokenPath, oauthPort))
+}
+
+// Connect is NewCalendarService without the authorization flow: it fails
+// when there is no usable cached token instead of asking for one.
+func Connect(credentialsPath, tokenPath string) (*CalendarService, error) {
+	config, err := oauthConfig(credentialsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	tok, err := tokenFromFile(tokenPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read cached OAuth token: %v", err)
+	}
+	return newService(config.Client(context.Background(), tok))
+}
+
+// Authorize runs the browser authorization flow and caches the new token in
+// tokenPath, replacing any token already there.
+func Authorize(credentialsPath, tokenPath string, oauthPort int) error {
+	config, err := oauthConfig(credentialsPath)
+	if err != nil {
+		return err
+	}
+	saveToken(tokenPath, getTokenFromWeb(config, oauthPort))
+	return nil
+}
+
+// CheckCredentials checks that credentialsPath holds a usable OAuth client.
+func CheckCredentials(credentialsPath string) error {
+	_, err := oauthConfig(credentialsPath)
+	return err
+}
+
+func oauthConfig(credentialsPath string) (*oauth2.Config, error) {
 	b, err := ioutil.ReadFile(credentialsPath)
 	if err != nil {
 		return nil, fmt.Errorf("unable to read client secret file: %v", err)
@@ -32,10 +79,11 @@ func NewCalendarService(credentialsPath string) (*CalendarService, error) {
 	if err != nil {
 		return nil, fmt.Errorf("unable to parse client secret file to config: %v", err)
 	}
-	
-	client := getClient(config)
-	
-	srv, err := calendar.NewService(ctx, option.WithHTTPClient(client))
+	return config, nil
+}
+
+func newService(client *http.Client) (*CalendarService, error) {
+	srv, err := calendar.NewService(context.Background(), option.WithHTTPClient(client))
 	if err != nil {
 		return nil, fmt.Errorf("unable to retrieve Calendar client: %v", err)
 	}
@@ -43,22 +91,26 @@ func NewCalendarService(credentialsPath string) (*CalendarService, error) {
 	return &CalendarService{service: srv}, nil
 }
 
-func getClient(config *oauth2.Config) *http.Client {
-	tokFile := "token.json"
+func getClient(config *oauth2.Config, tokFile string, oauthPort int) *http.Client {
 	tok, err := tokenFromFile(tokFile)
+	if errors.Is(err, crypt.ErrCannotDecrypt) {
+		// Authorizing again would overwrite a token that may only need the right key
+		logger.Error("unable to read cached OAuth token", "error", err)
+		os.Exit(1)
+	}
 	if err != nil {
-		tok = getTokenFromWeb(config)
+		tok = getTokenFromWeb(config, oauthPort)
 		saveToken(tokFile, tok)
 	}
 	return config.Client(context.Background(), tok)
 }
 
-func getTokenFromWeb(config *oauth2.Config) *oauth2.Token {
+func getTokenFromWeb(config *oauth2.Config, port int) *oauth2.Token {
 	// Start a local HTTP server to handle the callback
 	codeCh := make(chan string)
 	errCh := make(chan error)
 	
-	server := &http.Server{Addr: ":8000"}
+	server := &http.Server{Addr: fmt.Sprintf(":%d", port)}
 	
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		code := r.URL.Query().Get("code")
@@ -81,14 +133,14 @@ func getTokenFromWeb(config *oauth2.Config) *oauth2.Token {
 	})
 	
 	go func() {
-		log.Println("Starting OAuth callback server on :8000")
+		logger.Info("starting OAuth callback server", "addr", server.Addr)
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			errCh <- err
 		}
 	}()
 	
-	// Update config to use localhost:8000
-	config.RedirectURL = "http://localhost:8000"
+	// Update config to use the local callback server
+	config.RedirectURL = fmt.Sprintf("http://localhost:%d", port)
 	authURL := config.AuthCodeURL("state-token", oauth2.AccessTypeOffline)
 	fmt.Printf("🔗 Open this link in your browser to authorize the application:\n%v\n\n", authURL)
 	fmt.Println("⏳ Waiting for authorization... (will timeout in 5 minutes)")
@@ -98,9 +150,11 @@ func getTokenFromWeb(config *oauth2.Config) *oauth2.Token {
 	case authCode = <-codeCh:
 		fmt.Println("✅ Authorization received successfully!")
 	case err := <-errCh:
-		log.Fatalf("❌ Error during authorization: %v", err)
+		logger.Error("authorization failed", "error", err)
+		os.Exit(1)
 	case <-time.After(5 * time.Minute):
-		log.Fatalf("❌ Authorization timed out after 5 minutes")
+		logger.Error("authorization timed out", "after", "5m")
+		os.Exit(1)
 	}
 	
 	// Shutdown the server
@@ -110,30 +164,32 @@ func getTokenFromWeb(config *oauth2.Config) *oauth2.Token {
 
 	tok, err := config.Exchange(context.TODO(), authCode)
 	if err != nil {
-		log.Fatalf("Unable to retrieve token from web: %v", err)
+		logger.Error("unable to retrieve token from web", "error", err)
+		os.Exit(1)
 	}
 	return tok
 }
 
 func tokenFromFile(file string) (*oauth2.Token, error) {
-	f, err := os.Open(file)
+	data, err := crypt.ReadFile(file)
 	if err != nil {
 		return nil, err
 	}
-	defer f.Close()
 	tok := &oauth2.Token{}
-	err = json.NewDecoder(f).Decode(tok)
+	err = json.Unmarshal(data, tok)
 	return tok, err
 }
 
 func saveToken(path string, token *oauth2.Token) {
 	fmt.Printf("Saving credential file to: %s\n", path)
-	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	data, err := json.Marshal(token)
+	if err == nil {
+		err = crypt.WriteFile(path, data, 0600)
+	}
 	if err != nil {
-		log.Fatalf("Unable to cache oauth token: %v", err)
+		logger.Error("unable to cache OAuth token", "path", path, "error", err)
+		os.Exit(1)
 	}
-	defer f.Close()
-	json.NewEncoder(f).Encode(token)
 }
 
 func (cs *CalendarService) CreateEvent(title, description, startTime, endTime string) error {
@@ -141,23 +197,60 @@ func (cs *CalendarService) CreateEvent(title, description, startTime, endTime st
 }
 
 func (cs *CalendarService) CreateEventWithAttendees(title, description, startTime, endTime string, attendeeEmails []string) error {
-	event := &calendar.Event{
-		Summary:     title,
+	_, err := cs.InsertEvent(&EventRequest{
+		Title:       title,
 		Description: description,
+		StartTime:   startTime,
+		EndTime:     endTime,
+		Attendees:   attendeeEmails,
+	})
+	return err
+}
+
+// EventRequest describes an event to create. Recurrence holds RRULE lines,
+// StartTime/EndTime are RFC3339 and refer to the first occurrence. For AllDay
+// events they are YYYY-MM-DD dates and EndTime is the last day, inclusive.
+type EventRequest struct {
+	Title       string
+	Description string
+	StartTime   string
+	EndTime     string
+	AllDay      bool
+	Attendees   []string
+	Recurrence  []string
+	CalendarID  string // Defaults to the primary calendar
+	Conference  bool   // Ask Google to attach a Meet link
+}
+
+func (cs *CalendarService) InsertEvent(req *EventRequest) (*calendar.Event, error) {
+	event := &calendar.Event{
+		Summary:     req.Title,
+		Description: req.Description,
 		Start: &calendar.EventDateTime{
-			DateTime: startTime,
-			TimeZone: "Asia/Jakarta", // Indonesia timezone
+			DateTime: req.StartTime,
+			TimeZone: timezone.Name(),
 		},
 		End: &calendar.EventDateTime{
-			DateTime: endTime,
-			TimeZone: "Asia/Jakarta", // Indonesia timezone
+			DateTime: req.EndTime,
+			TimeZone: timezone.Name(),
 		},
+		Recurrence: req.Recurrence,
+	}
+
+	if req.AllDay {
+		lastDay, err := time.Parse("2006-01-02", req.EndTime)
+		if err != nil {
+			return nil, fmt.Errorf("invalid end date %q: %v", req.EndTime, err)
+		}
+		// Google's end date is exclusive
+		event.Start = &calendar.EventDateTime{Date: req.StartTime}
+		event.End = &calendar.EventDateTime{Date: lastDay.AddDate(0, 0, 1).Format("2006-01-02")}
 	}
 	
 	// Add attendees if provided
-	if len(attendeeEmails) > 0 {
+	if len(req.Attendees) > 0 {
 		var attendees []*calendar.EventAttendee
-		for _, email := range attendeeEmails {
+		for _, email := range req.Attendees {
 			attendees = append(attendees, &calendar.EventAttendee{
 				Email: email,
 			})
@@ -165,45 +258,27 @@ func (cs *CalendarService) CreateEventWithAttendees(title, description, startTim
 		event.Attendees = attendees
 	}
 
-	_, err := cs.service.Events.Insert("primary", event).Do()
-	return err
-}
+	calendarID := req.CalendarID
+	if calendarID == "" {
+		calendarID = "primary"
+	}
 
-func (cs *CalendarService) GetTodayEvents() ([]*calendar.Event, error) {
-	// Use Indonesia timezone
-	indonesiaLocation, _ := time.LoadLocation("Asia/Jakarta")
-	now := time.Now().In(indonesiaLocation)
-	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, indonesiaLocation)
-	endOfDay := startOfDay.Add(24 * time.Hour)
-
-	events, err := cs.service.Events.List("primary").
-		ShowDeleted(false).
-		SingleEvents(true).
-		TimeMin(startOfDay.Format(time.RFC3339)).
-		TimeMax(endOfDay.Format(time.RFC3339)).
-		OrderBy("startTime").Do()
-	
-	if err != nil {
-		return nil, err
+	call := cs.service.Events.Insert(calendarID, event)
+	if req.Conference {
+		event.ConferenceData = newMeetRequest()
+		call = call.ConferenceDataVersion(1)
 	}
 
-	return events.Items, nil
+	start := time.Now()
+	created, err := call.Do()
+	observe("events.insert", start, err)
+	return created, err
 }
 
-func (cs *CalendarService) GetUpcomingEvents(duration time.Duration) ([]*calendar.Event, error) {
-	now := time.Now()
-	later := now.Add(duration)
-
-	events, err := cs.service.Events.List("primary").
-		ShowDeleted(false).
-		SingleEvents(true).
-		TimeMin(now.Format(time.RFC3339)).
-		TimeMax(later.Format(time.RFC3339)).
-		OrderBy("startTime").Do()
-	
-	if err != nil {
-		return nil, err
-	}
+func (cs *CalendarService) GetTodayEvents() ([]*calendar.Event, error) {
+	return cs.GetTodayEventsFrom([]string{"primary"})
+}
 
-	return events.Items, nil
+func (cs *CalendarService) GetUpcomingEvents(duration time.Duration) ([]*calendar.Event, error) {
+	return cs.GetUpcomingEventsFrom([]string{"primary"}, duration)
 }
\ No newline at end of file
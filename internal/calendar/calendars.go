@@ -0,0 +1,128 @@
+package calendar
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+	"virtual-assistant/internal/timezone"
+)
+
+// ListCalendars returns every calendar on the user's calendar list, primary
+// first and the rest alphabetically.
+func (cs *CalendarService) ListCalendars() ([]*calendar.CalendarListEntry, error) {
+	var entries []*calendar.CalendarListEntry
+
+	call := cs.service.CalendarList.List().ShowHidden(false)
+	start := time.Now()
+	err := call.Pages(context.Background(), func(page *calendar.CalendarList) error {
+		entries = append(entries, page.Items...)
+		return nil
+	})
+	observe("calendarList.list", start, err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list calendars: %v", err)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Primary != entries[j].Primary {
+			return entries[i].Primary
+		}
+		return entries[i].Summary < entries[j].Summary
+	})
+
+	return entries, nil
+}
+
+// GetTodayEventsFrom aggregates today's events across several calendars.
+func (cs *CalendarService) GetTodayEventsFrom(calendarIDs []string) ([]*calendar.Event, error) {
+	return cs.GetEventsOn(calendarIDs, time.Now())
+}
+
+// GetEventsOn aggregates the events of the day containing day across several
+// calendars.
+func (cs *CalendarService) GetEventsOn(calendarIDs []string, day time.Time) ([]*calendar.Event, error) {
+	loc := timezone.Location()
+	day = day.In(loc)
+	startOfDay := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc)
+
+	return cs.listEvents(calendarIDs, startOfDay, startOfDay.AddDate(0, 0, 1))
+}
+
+// GetUpcomingEventsFrom aggregates events starting within duration across
+// several calendars.
+func (cs *CalendarService) GetUpcomingEventsFrom(calendarIDs []string, duration time.Duration) ([]*calendar.Event, error) {
+	now := time.Now()
+	return cs.listEvents(calendarIDs, now, now.Add(duration))
+}
+
+// listEvents merges events from several calendars ordered by start time.
+// The same meeting can sit on more than one calendar, so duplicates sharing
+// an iCalUID and start are dropped.
+func (cs *CalendarService) listEvents(calendarIDs []string, from, to time.Time) ([]*calendar.Event, error) {
+	if len(calendarIDs) == 0 {
+		calendarIDs = []string{"primary"}
+	}
+
+	seen := make(map[string]bool)
+	var merged []*calendar.Event
+	for _, calendarID := range calendarIDs {
+		start := time.Now()
+		events, err := cs.service.Events.List(calendarID).
+			ShowDeleted(false).
+			SingleEvents(true).
+			TimeMin(from.Format(time.RFC3339)).
+			TimeMax(to.Format(time.RFC3339)).
+			OrderBy("startTime").Do()
+		observe("events.list", start, err)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list events for calendar %s: %v", calendarID, err)
+		}
+
+		for _, event := range events.Items {
+			key := event.ICalUID + "_" + eventStartKey(event)
+			if event.ICalUID != "" && seen[key] {
+				continue
+			}
+			seen[key] = true
+			merged = append(merged, event)
+		}
+	}
+
+	if len(calendarIDs) > 1 {
+		sort.SliceStable(merged, func(i, j int) bool {
+			return eventStartKey(merged[i]) < eventStartKey(merged[j])
+		})
+	}
+
+	return merged, nil
+}
+
+// eventStartKey gives a sortable representation of the event start. All-day
+// dates sort before timed events on the same day.
+func eventStartKey(event *calendar.Event) string {
+	if event.Start == nil {
+		return ""
+	}
+	if event.Start.DateTime != "" {
+		if t, err := time.Parse(time.RFC3339, event.Start.DateTime); err == nil {
+			return t.UTC().Format(time.RFC3339)
+		}
+		return event.Start.DateTime
+	}
+	return event.Start.Date
+}
+
+// Health checks that the Calendar API can be reached with the stored token,
+// which also catches a token that has expired or been revoked.
+func (cs *CalendarService) Health(ctx context.Context) (string, error) {
+	start := time.Now()
+	primary, err := cs.service.Calendars.Get("primary").Context(ctx).Do()
+	observe("calendars.get", start, err)
+	if err != nil {
+		return "", fmt.Errorf("calendar API unreachable: %v", err)
+	}
+	return fmt.Sprintf("primary calendar in %s", primary.TimeZone), nil
+}
@@ -0,0 +1,51 @@
+package calendar
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// JoinURL returns the best link for joining the event's conference, or an
+// empty string when it has none. Video entry points win over the legacy
+// hangoutLink, which wins over phone/SIP/"more" entries.
+func JoinURL(event *calendar.Event) string {
+	if event.ConferenceData != nil {
+		for _, entryPoint := range event.ConferenceData.EntryPoints {
+			if entryPoint.EntryPointType == "video" && entryPoint.Uri != "" {
+				return entryPoint.Uri
+			}
+		}
+	}
+
+	if event.HangoutLink != "" {
+		return event.HangoutLink
+	}
+
+	if event.ConferenceData != nil {
+		for _, entryPoint := range event.ConferenceData.EntryPoints {
+			if strings.HasPrefix(entryPoint.Uri, "https://") {
+				return entryPoint.Uri
+			}
+		}
+	}
+
+	return ""
+}
+
+func newMeetRequest() *calendar.ConferenceData {
+	// The request ID only has to be unique per event
+	buf := make([]byte, 8)
+	rand.Read(buf)
+
+	return &calendar.ConferenceData{
+		CreateRequest: &calendar.CreateConferenceRequest{
+			RequestId: hex.EncodeToString(buf),
+			ConferenceSolutionKey: &calendar.ConferenceSolutionKey{
+				Type: "hangoutsMeet",
+			},
+		},
+	}
+}
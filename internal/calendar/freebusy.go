@@ -0,0 +1,235 @@
+package calendar
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+	"virtual-assistant/internal/timezone"
+)
+
+// Working hours used when suggesting alternative slots, in the local timezone
+const (
+	workdayStartHour = 8
+	workdayEndHour   = 18
+	slotStep         = 15 * time.Minute
+	searchWindowDays = 3
+)
+
+type BusyPeriod struct {
+	Start time.Time
+	End   time.Time
+}
+
+type Conflict struct {
+	Calendar string // Empty for our own calendars, otherwise the attendee email
+	Summary  string // Only known for our own events; free/busy hides titles
+	Start    time.Time
+	End      time.Time
+}
+
+type TimeSlot struct {
+	Start time.Time
+	End   time.Time
+}
+
+type ConflictReport struct {
+	Conflicts    []Conflict
+	Alternatives []TimeSlot
+	Unavailable  []string // Attendees whose free/busy we are not allowed to see
+}
+
+func (cr *ConflictReport) HasConflicts() bool {
+	return len(cr.Conflicts) > 0
+}
+
+// CheckConflicts looks for overlaps between [start, end) and our own calendars
+// plus every attendee calendar that exposes free/busy, and suggests the
+// nearest free slots of the same length when there is a clash.
+func (cs *CalendarService) CheckConflicts(calendarIDs []string, start, end time.Time, attendeeEmails []string, maxAlternatives int) (*ConflictReport, error) {
+	if !end.After(start) {
+		return nil, fmt.Errorf("end time must be after start time")
+	}
+
+	report := &ConflictReport{}
+
+	// Our own events give us titles, so list them instead of using free/busy
+	ownEvents, err := cs.listEvents(calendarIDs, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, event := range ownEvents {
+		if event.Transparency == "transparent" || event.Start.DateTime == "" {
+			continue // Free or all-day events don't block the slot
+		}
+		eventStart, err1 := time.Parse(time.RFC3339, event.Start.DateTime)
+		eventEnd, err2 := time.Parse(time.RFC3339, event.End.DateTime)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		if overlaps(start, end, eventStart, eventEnd) {
+			report.Conflicts = append(report.Conflicts, Conflict{
+				Summary: event.Summary,
+				Start:   eventStart,
+				End:     eventEnd,
+			})
+		}
+	}
+
+	if len(attendeeEmails) > 0 {
+		busy, unavailable, err := cs.queryFreeBusy(start, end, attendeeEmails)
+		if err != nil {
+			return nil, err
+		}
+		report.Unavailable = unavailable
+		for _, email := range attendeeEmails {
+			for _, period := range busy[email] {
+				if overlaps(start, end, period.Start, period.End) {
+					report.Conflicts = append(report.Conflicts, Conflict{
+						Calendar: email,
+						Start:    period.Start,
+						End:      period.End,
+					})
+				}
+			}
+		}
+	}
+
+	if report.HasConflicts() && maxAlternatives > 0 {
+		alternatives, err := cs.FindFreeSlots(calendarIDs, start, end.Sub(start), attendeeEmails, maxAlternatives)
+		if err != nil {
+			return nil, err
+		}
+		report.Alternatives = alternatives
+	}
+
+	return report, nil
+}
+
+// FindFreeSlots returns up to n free slots of the given duration, ordered by
+// distance from around, within working hours and a few days either side.
+func (cs *CalendarService) FindFreeSlots(calendarIDs []string, around time.Time, duration time.Duration, attendeeEmails []string, n int) ([]TimeSlot, error) {
+	loc := timezone.Location()
+	around = around.In(loc)
+
+	windowStart := around.AddDate(0, 0, -searchWindowDays)
+	now := time.Now().In(loc)
+	if windowStart.Before(now) {
+		windowStart = now
+	}
+	windowEnd := around.AddDate(0, 0, searchWindowDays)
+
+	if len(calendarIDs) == 0 {
+		calendarIDs = []string{"primary"}
+	}
+	busyByCalendar, _, err := cs.queryFreeBusy(windowStart, windowEnd, append(append([]string{}, calendarIDs...), attendeeEmails...))
+	if err != nil {
+		return nil, err
+	}
+
+	var busy []BusyPeriod
+	for _, periods := range busyByCalendar {
+		busy = append(busy, periods...)
+	}
+
+	var candidates []TimeSlot
+	slotStart := windowStart.Truncate(slotStep)
+	if slotStart.Before(windowStart) {
+		slotStart = slotStart.Add(slotStep)
+	}
+	for ; !slotStart.Add(duration).After(windowEnd); slotStart = slotStart.Add(slotStep) {
+		slotEnd := slotStart.Add(duration)
+		if !withinWorkingHours(slotStart, slotEnd) {
+			continue
+		}
+		if slotStart.Equal(around) {
+			continue // That's the slot we already know is taken
+		}
+		free := true
+		for _, period := range busy {
+			if overlaps(slotStart, slotEnd, period.Start, period.End) {
+				free = false
+				break
+			}
+		}
+		if free {
+			candidates = append(candidates, TimeSlot{Start: slotStart, End: slotEnd})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return absDuration(candidates[i].Start.Sub(around)) < absDuration(candidates[j].Start.Sub(around))
+	})
+
+	if len(candidates) > n {
+		candidates = candidates[:n]
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Start.Before(candidates[j].Start)
+	})
+
+	return candidates, nil
+}
+
+// queryFreeBusy returns busy periods keyed by calendar ID, along with the IDs
+// Google refused to share free/busy information for.
+func (cs *CalendarService) queryFreeBusy(start, end time.Time, calendarIDs []string) (map[string][]BusyPeriod, []string, error) {
+	var items []*calendar.FreeBusyRequestItem
+	for _, id := range calendarIDs {
+		items = append(items, &calendar.FreeBusyRequestItem{Id: id})
+	}
+
+	callStart := time.Now()
+	resp, err := cs.service.Freebusy.Query(&calendar.FreeBusyRequest{
+		TimeMin:  start.Format(time.RFC3339),
+		TimeMax:  end.Format(time.RFC3339),
+		TimeZone: timezone.Name(),
+		Items:    items,
+	}).Do()
+	observe("freebusy.query", callStart, err)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query free/busy: %v", err)
+	}
+
+	busy := make(map[string][]BusyPeriod)
+	var unavailable []string
+	for id, cal := range resp.Calendars {
+		if len(cal.Errors) > 0 {
+			unavailable = append(unavailable, id)
+			continue
+		}
+		for _, period := range cal.Busy {
+			periodStart, err1 := time.Parse(time.RFC3339, period.Start)
+			periodEnd, err2 := time.Parse(time.RFC3339, period.End)
+			if err1 != nil || err2 != nil {
+				continue
+			}
+			busy[id] = append(busy[id], BusyPeriod{Start: periodStart, End: periodEnd})
+		}
+	}
+	sort.Strings(unavailable)
+
+	return busy, unavailable, nil
+}
+
+func overlaps(aStart, aEnd, bStart, bEnd time.Time) bool {
+	return aStart.Before(bEnd) && bStart.Before(aEnd)
+}
+
+func withinWorkingHours(start, end time.Time) bool {
+	if start.Weekday() == time.Saturday || start.Weekday() == time.Sunday {
+		return false
+	}
+	dayStart := time.Date(start.Year(), start.Month(), start.Day(), workdayStartHour, 0, 0, 0, start.Location())
+	dayEnd := time.Date(start.Year(), start.Month(), start.Day(), workdayEndHour, 0, 0, 0, start.Location())
+	return !start.Before(dayStart) && !end.After(dayEnd)
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,35 @@
+package calendar
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	apiCalls = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "assistant_calendar_api_calls_total",
+		Help: "Google Calendar API calls, by method.",
+	}, []string{"method"})
+
+	apiErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "assistant_calendar_api_errors_total",
+		Help: "Google Calendar API calls that failed, by method.",
+	}, []string{"method"})
+
+	apiDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "assistant_calendar_api_duration_seconds",
+		Help:    "Google Calendar API call latency, by method.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method"})
+)
+
+// observe records a Calendar API call that started at start.
+func observe(method string, start time.Time, err error) {
+	apiCalls.WithLabelValues(method).Inc()
+	apiDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	if err != nil {
+		apiErrors.WithLabelValues(method).Inc()
+	}
+}
@@ -0,0 +1,152 @@
+package calendar
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"virtual-assistant/internal/i18n"
+)
+
+var validFrequencies = map[string]bool{
+	"DAILY":   true,
+	"WEEKLY":  true,
+	"MONTHLY": true,
+	"YEARLY":  true,
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// ParseRecurrence normalises an RRULE coming from the LLM and validates it
+// against the subset of RFC 5545 we support. An empty or "none" rule returns
+// an empty string and no error.
+func ParseRecurrence(rule string) (string, error) {
+	rule = strings.TrimSpace(rule)
+	if rule == "" || strings.EqualFold(rule, "none") || strings.EqualFold(rule, "empty") {
+		return "", nil
+	}
+
+	rule = strings.ToUpper(rule)
+	if !strings.HasPrefix(rule, "RRULE:") {
+		rule = "RRULE:" + rule
+	}
+
+	parts, err := splitRecurrence(rule)
+	if err != nil {
+		return "", err
+	}
+
+	freq, ok := parts["FREQ"]
+	if !ok {
+		return "", fmt.Errorf("recurrence rule is missing FREQ")
+	}
+	if !validFrequencies[freq] {
+		return "", fmt.Errorf("unsupported recurrence frequency %q", freq)
+	}
+
+	if _, hasUntil := parts["UNTIL"]; hasUntil {
+		if _, hasCount := parts["COUNT"]; hasCount {
+			return "", fmt.Errorf("recurrence rule can't have both UNTIL and COUNT")
+		}
+	}
+
+	for key, value := range parts {
+		switch key {
+		case "FREQ":
+		case "INTERVAL", "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return "", fmt.Errorf("invalid %s %q in recurrence rule", key, value)
+			}
+		case "UNTIL":
+			if _, err := parseUntil(value); err != nil {
+				return "", fmt.Errorf("invalid UNTIL %q in recurrence rule", value)
+			}
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				// Allow ordinal prefixes such as 1MO or -1FR for monthly rules
+				name := strings.TrimLeft(day, "+-0123456789")
+				if _, ok := weekdayNames[name]; !ok {
+					return "", fmt.Errorf("invalid BYDAY %q in recurrence rule", day)
+				}
+			}
+		case "BYMONTHDAY", "BYMONTH", "BYSETPOS", "WKST":
+			// Passed through to Google untouched
+		default:
+			return "", fmt.Errorf("unsupported recurrence field %q", key)
+		}
+	}
+
+	return rule, nil
+}
+
+// DescribeRecurrence turns a validated RRULE into a short human sentence.
+func DescribeRecurrence(lang i18n.Language, rule string) string {
+	parts, err := splitRecurrence(rule)
+	if err != nil {
+		return rule
+	}
+
+	description := i18n.T(lang, "recurrence.every."+parts["FREQ"])
+	if interval, err := strconv.Atoi(parts["INTERVAL"]); err == nil && interval > 1 {
+		description = i18n.T(lang, "recurrence.every_n."+parts["FREQ"], interval)
+	}
+
+	if byDay, ok := parts["BYDAY"]; ok {
+		var days []string
+		for _, day := range strings.Split(byDay, ",") {
+			ordinal := strings.TrimRight(day, "ABCDEFGHIJKLMNOPQRSTUVWXYZ")
+			name := i18n.WeekdayName(lang, weekdayNames[strings.TrimPrefix(day, ordinal)])
+			if ordinal != "" {
+				name = ordinal + " " + name
+			}
+			days = append(days, name)
+		}
+		description += i18n.T(lang, "recurrence.on", strings.Join(days, ", "))
+	}
+
+	if until, ok := parts["UNTIL"]; ok {
+		if t, err := parseUntil(until); err == nil {
+			description += i18n.T(lang, "recurrence.until", i18n.FormatTime(lang, t, "02 Jan 2006"))
+		}
+	}
+	if count, ok := parts["COUNT"]; ok {
+		description += i18n.T(lang, "recurrence.count", count)
+	}
+
+	return description
+}
+
+func splitRecurrence(rule string) (map[string]string, error) {
+	body := strings.TrimPrefix(rule, "RRULE:")
+	parts := make(map[string]string)
+	for _, field := range strings.Split(body, ";") {
+		if field == "" {
+			continue
+		}
+		kv := strings.SplitN(field, "=", 2)
+		if len(kv) != 2 || kv[1] == "" {
+			return nil, fmt.Errorf("malformed recurrence field %q", field)
+		}
+		parts[kv[0]] = kv[1]
+	}
+	return parts, nil
+}
+
+func parseUntil(value string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognised date")
+}
@@ -0,0 +1,129 @@
+package calendar
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+	"virtual-assistant/internal/timezone"
+)
+
+// EditScope says whether a change applies to one occurrence of a recurring
+// event or to the whole series.
+type EditScope string
+
+const (
+	ScopeOccurrence EditScope = "OCCURRENCE"
+	ScopeSeries     EditScope = "SERIES"
+)
+
+func ParseEditScope(value string) EditScope {
+	if strings.EqualFold(strings.TrimSpace(value), string(ScopeSeries)) {
+		return ScopeSeries
+	}
+	return ScopeOccurrence
+}
+
+// FindEvent looks up the first event on the given calendars whose title
+// contains the query, on the given day or, when day is zero, within the next
+// 30 days. Recurring events are expanded so the result is always a concrete
+// occurrence. The calendar the event lives on is returned alongside it.
+func (cs *CalendarService) FindEvent(calendarIDs []string, query string, day time.Time) (*calendar.Event, string, error) {
+	loc := timezone.Location()
+
+	var from, to time.Time
+	if day.IsZero() {
+		from = time.Now()
+		to = from.AddDate(0, 0, 30)
+	} else {
+		day = day.In(loc)
+		from = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc)
+		to = from.Add(24 * time.Hour)
+	}
+
+	if len(calendarIDs) == 0 {
+		calendarIDs = []string{"primary"}
+	}
+
+	query = strings.ToLower(strings.TrimSpace(query))
+	for _, calendarID := range calendarIDs {
+		start := time.Now()
+		events, err := cs.service.Events.List(calendarID).
+			ShowDeleted(false).
+			SingleEvents(true).
+			TimeMin(from.Format(time.RFC3339)).
+			TimeMax(to.Format(time.RFC3339)).
+			OrderBy("startTime").Do()
+		observe("events.list", start, err)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to list events: %v", err)
+		}
+
+		for _, event := range events.Items {
+			if strings.Contains(strings.ToLower(event.Summary), query) {
+				return event, calendarID, nil
+			}
+		}
+	}
+
+	return nil, "", nil
+}
+
+// CancelEvent deletes a single occurrence or, for ScopeSeries, the recurring
+// event it belongs to.
+func (cs *CalendarService) CancelEvent(calendarID string, occurrence *calendar.Event, scope EditScope) error {
+	eventID := occurrence.Id
+	if scope == ScopeSeries && occurrence.RecurringEventId != "" {
+		eventID = occurrence.RecurringEventId
+	}
+	start := time.Now()
+	err := cs.service.Events.Delete(calendarID, eventID).Do()
+	observe("events.delete", start, err)
+	return err
+}
+
+// RescheduleEvent moves a single occurrence to [start, end). For ScopeSeries
+// the series keeps its first date but takes the new time of day and length.
+func (cs *CalendarService) RescheduleEvent(calendarID string, occurrence *calendar.Event, scope EditScope, start, end time.Time) error {
+	if !end.After(start) {
+		return fmt.Errorf("end time must be after start time")
+	}
+
+	if scope != ScopeSeries || occurrence.RecurringEventId == "" {
+		callStart := time.Now()
+		_, err := cs.service.Events.Patch(calendarID, occurrence.Id, &calendar.Event{
+			Start: &calendar.EventDateTime{DateTime: start.Format(time.RFC3339), TimeZone: timezone.Name()},
+			End:   &calendar.EventDateTime{DateTime: end.Format(time.RFC3339), TimeZone: timezone.Name()},
+		}).Do()
+		observe("events.patch", callStart, err)
+		return err
+	}
+
+	callStart := time.Now()
+	series, err := cs.service.Events.Get(calendarID, occurrence.RecurringEventId).Do()
+	observe("events.get", callStart, err)
+	if err != nil {
+		return fmt.Errorf("failed to load recurring event: %v", err)
+	}
+
+	seriesStart, err := time.Parse(time.RFC3339, series.Start.DateTime)
+	if err != nil {
+		return fmt.Errorf("recurring event has no start time: %v", err)
+	}
+
+	loc := timezone.Location()
+	seriesStart = seriesStart.In(loc)
+	start = start.In(loc)
+	newStart := time.Date(seriesStart.Year(), seriesStart.Month(), seriesStart.Day(),
+		start.Hour(), start.Minute(), 0, 0, loc)
+	newEnd := newStart.Add(end.Sub(start))
+
+	callStart = time.Now()
+	_, err = cs.service.Events.Patch(calendarID, series.Id, &calendar.Event{
+		Start: &calendar.EventDateTime{DateTime: newStart.Format(time.RFC3339), TimeZone: timezone.Name()},
+		End:   &calendar.EventDateTime{DateTime: newEnd.Format(time.RFC3339), TimeZone: timezone.Name()},
+	}).Do()
+	observe("events.patch", callStart, err)
+	return err
+}
@@ -1,37 +1,459 @@
+// Package config loads the assistant's settings from an optional YAML file,
+// overridden by environment variables, and checks them before startup.
 package config
 
 import (
-	"log"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
 	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+	"virtual-assistant/internal/crypt"
+	"virtual-assistant/internal/logging"
+	"virtual-assistant/internal/timezone"
 )
 
+var logger = logging.For("config")
+
+var dotenvOnce sync.Once
+
+// DefaultFile is read when it exists and no other file is given.
+const DefaultFile = "config.yaml"
+
+// ProviderClaudeCode runs the claude CLI; it's the only provider so far.
+const ProviderClaudeCode = "claude-code"
+
+// Shorter API tokens are too easy to guess
+const minAPITokenLength = 16
+
+// Every setting has a key in the file and an environment variable, which
+// wins when set. Fields tagged secret are masked when the config is printed,
+// and fields tagged reload can change while the assistant runs.
 type Config struct {
-	TelegramBotToken     string
-	GoogleCredentialsPath string
-	ClaudeCodePath       string
-	WebhookURL           string
-	Port                 string
+	Timezone  string          `yaml:"timezone" env:"TIMEZONE"` // IANA name used for "today", event times and reminders
+	Telegram  TelegramConfig  `yaml:"telegram"`
+	Server    ServerConfig    `yaml:"server"`
+	Access    AccessConfig    `yaml:"access"`
+	Reminders RemindersConfig `yaml:"reminders"`
+	Events    EventsConfig    `yaml:"events"`
+	LLM       LLMConfig       `yaml:"llm"`
+	Storage   StorageConfig   `yaml:"storage"`
+	Shutdown  ShutdownConfig  `yaml:"shutdown"`
+	Logging   LoggingConfig   `yaml:"logging"`
+
+	File    string `yaml:"-"` // File the config was read from, if any
+	Profile string `yaml:"-"` // Profile applied on top of the file, if any
+}
+
+type TelegramConfig struct {
+	Token                   string        `yaml:"token" env:"TELEGRAM_BOT_TOKEN" secret:"true"`
+	ChatID                  int64         `yaml:"chat_id" env:"CHAT_ID"`                                       // Chat that always gets reminders, even before it messages the bot
+	WebhookURL              string        `yaml:"webhook_url" env:"WEBHOOK_URL"`                               // Public HTTPS base URL; polling is used when empty
+	WebhookSecret           string        `yaml:"webhook_secret" env:"WEBHOOK_SECRET" secret:"true"`           // Secret Telegram sends with each update; random per run if empty
+	WebhookAllowedIPs       string        `yaml:"webhook_allowed_ips" env:"WEBHOOK_ALLOWED_IPS"`               // Comma-separated CIDRs or "telegram" to limit who may call the webhook
+	WebhookTrustProxy       bool          `yaml:"webhook_trust_proxy" env:"WEBHOOK_TRUST_PROXY"`               // Read the caller's address from X-Forwarded-For
+	DeleteWebhookOnShutdown bool          `yaml:"delete_webhook_on_shutdown" env:"DELETE_WEBHOOK_ON_SHUTDOWN"` // Unregister the webhook on shutdown instead of letting Telegram queue updates for it
+	Workers                 int           `yaml:"workers" env:"WORKERS"`                                       // Updates handled at the same time
+	QueueSize               int           `yaml:"queue_size" env:"QUEUE_SIZE"`                                 // Updates that may wait for a worker before Telegram is pushed back
+	UpdateTimeout           time.Duration `yaml:"update_timeout" env:"UPDATE_TIMEOUT"`                         // Deadline for handling a single update
+}
+
+type ServerConfig struct {
+	Port      int    `yaml:"port" env:"PORT"`                         // Webhook, health checks, metrics and the REST API
+	OAuthPort int    `yaml:"oauth_port" env:"OAUTH_PORT"`             // Callback for the first-run Google authorization
+	APIToken  string `yaml:"api_token" env:"API_TOKEN" secret:"true"` // Bearer token for the REST API; the API is off when empty
+}
+
+type AccessConfig struct {
+	AllowedChats []int64 `yaml:"allowed_chats" env:"ALLOWED_CHAT_IDS" reload:"true"` // Chats that may use the bot; empty allows everyone
+	Owners       []int64 `yaml:"owners" env:"OWNER_IDS" reload:"true"`               // Users who can use every admin command
+}
+
+type RemindersConfig struct {
+	Tiers            []time.Duration `yaml:"tiers" env:"REMINDER_TIERS" reload:"true"`                    // How long before a meeting reminders go out, one per tier
+	AllDayNotice     bool            `yaml:"all_day_notice" env:"ALL_DAY_NOTICE" reload:"true"`           // Send a day-before notice for all-day events
+	AllDayNoticeHour int             `yaml:"all_day_notice_hour" env:"ALL_DAY_NOTICE_HOUR" reload:"true"` // Local hour the day-before notice goes out
+	QuietFrom        int             `yaml:"quiet_from" env:"QUIET_HOURS_FROM" reload:"true"`             // Local hour from which no reminders are sent; off when equal to quiet_until
+	QuietUntil       int             `yaml:"quiet_until" env:"QUIET_HOURS_UNTIL" reload:"true"`           // Local hour reminders resume
+}
+
+type EventsConfig struct {
+	MeetByDefault bool `yaml:"meet_by_default" env:"MEET_BY_DEFAULT" reload:"true"` // Attach a Google Meet link to new events unless they're in person
+}
+
+type LLMConfig struct {
+	Provider     string        `yaml:"provider" env:"LLM_PROVIDER"`
+	Path         string        `yaml:"path" env:"CLAUDE_CODE_PATH"`                       // claude executable
+	Concurrency  int           `yaml:"concurrency" env:"LLM_CONCURRENCY"`                 // claude processes allowed to run at once
+	QueueSize    int           `yaml:"queue_size" env:"LLM_QUEUE_SIZE"`                   // Requests that may wait for a claude process before users are told it's busy
+	Timeout      time.Duration `yaml:"timeout" env:"LLM_TIMEOUT" reload:"true"`           // Deadline for a single claude run
+	Instructions string        `yaml:"instructions" env:"LLM_INSTRUCTIONS" reload:"true"` // Template added to every prompt, e.g. a tone or house rules; can't change the answer format
+}
+
+type StorageConfig struct {
+	Dir               string   `yaml:"dir" env:"DATA_DIR"`                                          // Relative paths below are resolved against it; the working directory when empty
+	GoogleCredentials string   `yaml:"google_credentials" env:"GOOGLE_CREDENTIALS_PATH"`            // OAuth client from the Google Cloud Console
+	GoogleToken       string   `yaml:"google_token" env:"GOOGLE_TOKEN_PATH"`                        // OAuth token saved after the first authorization
+	Database          string   `yaml:"database" env:"DATABASE_PATH"`                                // Users, chats, settings, sent reminders, history, contacts and RSVP state
+	HistoryLimit      int      `yaml:"history_limit" env:"HISTORY_LIMIT" reload:"true"`             // Messages kept per chat; 0 keeps none
+	ChatIDs           string   `yaml:"chat_ids" env:"CHAT_IDS_PATH"`                                // Imported into the database on first start
+	Settings          string   `yaml:"settings" env:"SETTINGS_PATH"`                                // Imported into the database on first start
+	Contacts          string   `yaml:"contacts" env:"CONTACTS_PATH"`                                // Imported into the database on first start
+	RSVPState         string   `yaml:"rsvp_state" env:"RSVP_STATE_PATH"`                            // Imported into the database on first start
+	EncryptionKey     string   `yaml:"encryption_key" env:"ENCRYPTION_KEY" secret:"true"`           // Encrypts the token and the database; plaintext when empty
+	OldEncryptionKeys []string `yaml:"old_encryption_keys" env:"OLD_ENCRYPTION_KEYS" secret:"true"` // Previous keys, still accepted for reading after a rotation
 }
 
-func Load() *Config {
-	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found, using environment variables")
+// Files returns the files the assistant writes besides the database, which
+// are the ones encrypted at rest, by key.
+func (s StorageConfig) Files() map[string]string {
+	return map[string]string{
+		"storage.google_token": s.GoogleToken,
 	}
+}
+
+// resolve makes the storage paths relative to Dir.
+func (s *StorageConfig) resolve() {
+	if s.Dir == "" {
+		return
+	}
+	for _, path := range []*string{&s.GoogleCredentials, &s.GoogleToken, &s.Database, &s.ChatIDs, &s.Settings, &s.Contacts, &s.RSVPState} {
+		if *path != "" && !filepath.IsAbs(*path) {
+			*path = filepath.Join(s.Dir, *path)
+		}
+	}
+}
+
+type ShutdownConfig struct {
+	DrainTimeout time.Duration `yaml:"drain_timeout" env:"DRAIN_TIMEOUT"` // How long shutdown waits for in-flight updates before cancelling them
+	Timeout      time.Duration `yaml:"timeout" env:"SHUTDOWN_TIMEOUT"`    // Deadline for the whole shutdown
+}
+
+type LoggingConfig struct {
+	Level  string `yaml:"level" env:"LOG_LEVEL" reload:"true"`   // debug, info, warn or error
+	Levels string `yaml:"levels" env:"LOG_LEVELS" reload:"true"` // Per-package overrides, e.g. "bot=debug,calendar=warn"
+	Format string `yaml:"format" env:"LOG_FORMAT" reload:"true"` // json or text
+	Redact bool   `yaml:"redact" env:"LOG_REDACT" reload:"true"` // Keep message text, emails and names out of the logs
+}
 
+// Default returns the settings used when neither the file nor the
+// environment says otherwise.
+func Default() *Config {
 	return &Config{
-		TelegramBotToken:     getEnv("TELEGRAM_BOT_TOKEN", ""),
-		GoogleCredentialsPath: getEnv("GOOGLE_CREDENTIALS_PATH", "credentials.json"),
-		ClaudeCodePath:       getEnv("CLAUDE_CODE_PATH", "claude"),
-		WebhookURL:           getEnv("WEBHOOK_URL", ""),
-		Port:                 getEnv("PORT", "8080"),
+		Timezone: timezone.Default,
+		Telegram: TelegramConfig{
+			Workers:       8,
+			QueueSize:     100,
+			UpdateTimeout: 2 * time.Minute,
+		},
+		Server: ServerConfig{Port: 8080, OAuthPort: 8000},
+		Reminders: RemindersConfig{
+			Tiers:            []time.Duration{10 * time.Minute},
+			AllDayNotice:     true,
+			AllDayNoticeHour: 18,
+		},
+		LLM: LLMConfig{
+			Provider:    ProviderClaudeCode,
+			Path:        "claude",
+			Concurrency: 2,
+			QueueSize:   20,
+			Timeout:     30 * time.Second,
+		},
+		Storage: StorageConfig{
+			GoogleCredentials: "credentials.json",
+			GoogleToken:       "token.json",
+			Database:          "assistant.db",
+			HistoryLimit:      50,
+			ChatIDs:           "chat_ids.json",
+			Settings:          "user_settings.json",
+			Contacts:          "contacts.json",
+			RSVPState:         "rsvp_state.json",
+		},
+		Shutdown: ShutdownConfig{
+			DrainTimeout: 20 * time.Second,
+			Timeout:      30 * time.Second,
+		},
+		Logging: LoggingConfig{Level: "info", Format: "json", Redact: true},
+	}
+}
+
+// file is the layout of the config file: the settings themselves, plus named
+// profiles that override some of them.
+type file struct {
+	Config   `yaml:",inline"`
+	Profiles map[string]yaml.Node `yaml:"profiles"`
+}
+
+// Load reads the config file at path, applies profile from its profiles
+// section and then the environment, including a .env file. An empty path
+// means $CONFIG_FILE, or config.yaml if it exists; an empty profile means
+// $PROFILE. Load only reports settings it can't read; call Validate to check
+// their values.
+func Load(path, profile string) (*Config, error) {
+	// Variables already set win over .env, so reading it again would change nothing
+	dotenvOnce.Do(func() {
+		if err := godotenv.Load(); err != nil {
+			logger.Info("no .env file found, using environment variables")
+		}
+	})
+
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	if path == "" {
+		if _, err := os.Stat(DefaultFile); err == nil {
+			path = DefaultFile
+		}
+	}
+	if profile == "" {
+		profile = os.Getenv("PROFILE")
+	}
+
+	cfg := Default()
+	if path != "" {
+		if err := cfg.readFile(path, profile); err != nil {
+			return nil, err
+		}
+	} else if profile != "" {
+		return nil, fmt.Errorf("profile %q given but there is no config file to read it from", profile)
+	}
+
+	if err := applyEnv(cfg); err != nil {
+		return nil, err
+	}
+	cfg.Storage.resolve()
+	return cfg, nil
+}
+
+func (cfg *Config) readFile(path, profile string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %v", err)
+	}
+
+	f := file{Config: *cfg}
+	if err := decodeStrict(data, &f); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	*cfg = f.Config
+	cfg.File = path
+
+	if profile == "" {
+		return nil
+	}
+	node, ok := f.Profiles[profile]
+	if !ok {
+		names := make([]string, 0, len(f.Profiles))
+		for name := range f.Profiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("%s: no profile %q (have: %s)", path, profile, strings.Join(names, ", "))
+	}
+
+	// Round-trip through YAML so typos in a profile are caught like in the rest of the file
+	data, err = yaml.Marshal(&node)
+	if err != nil {
+		return fmt.Errorf("%s: profile %q: %v", path, profile, err)
+	}
+	if err := decodeStrict(data, cfg); err != nil {
+		return fmt.Errorf("%s: profile %q: %v", path, profile, err)
 	}
+	cfg.Profile = profile
+	return nil
 }
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// decodeStrict decodes YAML, rejecting keys that don't match a setting.
+func decodeStrict(data []byte, out interface{}) error {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(out); err != nil && !errors.Is(err, io.EOF) {
+		return err
 	}
-	return defaultValue
-}
\ No newline at end of file
+	return nil
+}
+
+// Validate reports every invalid setting at once, naming both the file key
+// and the environment variable.
+func (cfg *Config) Validate() error {
+	var errs []error
+	invalid := func(key, env, format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%s (%s): %s", key, env, fmt.Sprintf(format, args...)))
+	}
+
+	if _, err := time.LoadLocation(cfg.Timezone); err != nil || cfg.Timezone == "" {
+		invalid("timezone", "TIMEZONE", "unknown timezone %q", cfg.Timezone)
+	}
+
+	t := cfg.Telegram
+	if t.Token == "" {
+		invalid("telegram.token", "TELEGRAM_BOT_TOKEN", "is required")
+	}
+	if t.WebhookURL != "" {
+		if u, err := url.Parse(t.WebhookURL); err != nil || u.Scheme != "https" || u.Host == "" {
+			invalid("telegram.webhook_url", "WEBHOOK_URL", "must be an https:// URL, got %q", t.WebhookURL)
+		}
+	}
+	if t.Workers < 1 {
+		invalid("telegram.workers", "WORKERS", "must be at least 1, got %d", t.Workers)
+	}
+	if t.QueueSize < 1 {
+		invalid("telegram.queue_size", "QUEUE_SIZE", "must be at least 1, got %d", t.QueueSize)
+	}
+	if t.UpdateTimeout <= 0 {
+		invalid("telegram.update_timeout", "UPDATE_TIMEOUT", "must be positive, got %s", t.UpdateTimeout)
+	}
+
+	if !validPort(cfg.Server.Port) {
+		invalid("server.port", "PORT", "must be between 1 and 65535, got %d", cfg.Server.Port)
+	}
+	if !validPort(cfg.Server.OAuthPort) {
+		invalid("server.oauth_port", "OAUTH_PORT", "must be between 1 and 65535, got %d", cfg.Server.OAuthPort)
+	} else if cfg.Server.OAuthPort == cfg.Server.Port {
+		invalid("server.oauth_port", "OAUTH_PORT", "must differ from server.port")
+	}
+	if cfg.Server.APIToken != "" && len(cfg.Server.APIToken) < minAPITokenLength {
+		invalid("server.api_token", "API_TOKEN", "must be at least %d characters", minAPITokenLength)
+	}
+
+	r := cfg.Reminders
+	if len(r.Tiers) == 0 {
+		invalid("reminders.tiers", "REMINDER_TIERS", "needs at least one tier")
+	}
+	seen := make(map[time.Duration]bool)
+	for _, tier := range r.Tiers {
+		if tier <= 0 {
+			invalid("reminders.tiers", "REMINDER_TIERS", "tiers must be positive, got %s", tier)
+		} else if seen[tier] {
+			invalid("reminders.tiers", "REMINDER_TIERS", "tier %s is listed twice", tier)
+		}
+		seen[tier] = true
+	}
+	if r.AllDayNoticeHour < 0 || r.AllDayNoticeHour > 23 {
+		invalid("reminders.all_day_notice_hour", "ALL_DAY_NOTICE_HOUR", "must be between 0 and 23, got %d", r.AllDayNoticeHour)
+	}
+	if r.QuietFrom < 0 || r.QuietFrom > 23 {
+		invalid("reminders.quiet_from", "QUIET_HOURS_FROM", "must be between 0 and 23, got %d", r.QuietFrom)
+	}
+	if r.QuietUntil < 0 || r.QuietUntil > 23 {
+		invalid("reminders.quiet_until", "QUIET_HOURS_UNTIL", "must be between 0 and 23, got %d", r.QuietUntil)
+	}
+
+	l := cfg.LLM
+	if l.Provider != ProviderClaudeCode {
+		invalid("llm.provider", "LLM_PROVIDER", "unknown provider %q, only %q is supported", l.Provider, ProviderClaudeCode)
+	}
+	if l.Path == "" {
+		invalid("llm.path", "CLAUDE_CODE_PATH", "is required (path to the claude executable)")
+	}
+	if l.Concurrency < 1 {
+		invalid("llm.concurrency", "LLM_CONCURRENCY", "must be at least 1, got %d", l.Concurrency)
+	}
+	if l.QueueSize < 0 {
+		invalid("llm.queue_size", "LLM_QUEUE_SIZE", "can't be negative, got %d", l.QueueSize)
+	}
+	if l.Timeout <= 0 {
+		invalid("llm.timeout", "LLM_TIMEOUT", "must be positive, got %s", l.Timeout)
+	}
+
+	s := cfg.Storage
+	for _, path := range []struct{ key, env, value string }{
+		{"storage.google_credentials", "GOOGLE_CREDENTIALS_PATH", s.GoogleCredentials},
+		{"storage.google_token", "GOOGLE_TOKEN_PATH", s.GoogleToken},
+		{"storage.database", "DATABASE_PATH", s.Database},
+		{"storage.chat_ids", "CHAT_IDS_PATH", s.ChatIDs},
+		{"storage.settings", "SETTINGS_PATH", s.Settings},
+		{"storage.contacts", "CONTACTS_PATH", s.Contacts},
+		{"storage.rsvp_state", "RSVP_STATE_PATH", s.RSVPState},
+	} {
+		if path.value == "" {
+			invalid(path.key, path.env, "is required")
+		}
+	}
+	if s.HistoryLimit < 0 {
+		invalid("storage.history_limit", "HISTORY_LIMIT", "can't be negative, got %d", s.HistoryLimit)
+	}
+	if s.Dir != "" {
+		if info, err := os.Stat(s.Dir); err != nil || !info.IsDir() {
+			invalid("storage.dir", "DATA_DIR", "%q is not a directory", s.Dir)
+		}
+	}
+	if s.EncryptionKey != "" {
+		if err := crypt.ParseKey(s.EncryptionKey); err != nil {
+			invalid("storage.encryption_key", "ENCRYPTION_KEY", "%v; generate one with `keys generate`", err)
+		}
+	}
+	for i, key := range s.OldEncryptionKeys {
+		if err := crypt.ParseKey(key); err != nil {
+			invalid("storage.old_encryption_keys", "OLD_ENCRYPTION_KEYS", "key %d: %v", i+1, err)
+		}
+	}
+	if len(s.OldEncryptionKeys) > 0 && s.EncryptionKey == "" {
+		invalid("storage.old_encryption_keys", "OLD_ENCRYPTION_KEYS", "set storage.encryption_key too; files are only re-encrypted with a current key")
+	}
+
+	if cfg.Shutdown.DrainTimeout <= 0 {
+		invalid("shutdown.drain_timeout", "DRAIN_TIMEOUT", "must be positive, got %s", cfg.Shutdown.DrainTimeout)
+	}
+	if cfg.Shutdown.Timeout < cfg.Shutdown.DrainTimeout {
+		invalid("shutdown.timeout", "SHUTDOWN_TIMEOUT", "must be at least shutdown.drain_timeout (%s), got %s", cfg.Shutdown.DrainTimeout, cfg.Shutdown.Timeout)
+	}
+
+	g := cfg.Logging
+	if err := validLevel(g.Level); err != nil {
+		invalid("logging.level", "LOG_LEVEL", "%v", err)
+	}
+	for _, entry := range strings.Split(g.Levels, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		pkg, level, found := strings.Cut(entry, "=")
+		if !found || strings.TrimSpace(pkg) == "" {
+			invalid("logging.levels", "LOG_LEVELS", "expected package=level, got %q", entry)
+		} else if err := validLevel(level); err != nil {
+			invalid("logging.levels", "LOG_LEVELS", "%s: %v", strings.TrimSpace(pkg), err)
+		}
+	}
+	if format := strings.ToLower(g.Format); format != "json" && format != "text" {
+		invalid("logging.format", "LOG_FORMAT", "must be json or text, got %q", g.Format)
+	}
+
+	return errors.Join(errs...)
+}
+
+func validPort(port int) bool {
+	return port >= 1 && port <= 65535
+}
+
+func validLevel(value string) error {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.TrimSpace(value))); err != nil {
+		return fmt.Errorf("unknown level %q, expected debug, info, warn or error", value)
+	}
+	return nil
+}
+
+// Masked returns the config as YAML with secrets hidden, for printing.
+func (cfg *Config) Masked() ([]byte, error) {
+	masked := *cfg
+	mask(reflect.ValueOf(&masked).Elem())
+
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&masked); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// applyEnv overrides every setting whose environment variable is set. Empty
+// variables count as unset, so a blank line in .env keeps the default.
+// Secrets can also be read from the file named by NAME_FILE, which is how
+// Docker and Kubernetes mount them.
+func applyEnv(cfg *Config) error {
+	return walk(reflect.ValueOf(cfg).Elem(), "", func(key string, field reflect.StructField, value reflect.Value) error {
+		name := field.Tag.Get("env")
+		if name == "" {
+			return nil
+		}
+		raw := strings.TrimSpace(os.Getenv(name))
+		if raw == "" && field.Tag.Get("secret") == "true" {
+			path := strings.TrimSpace(os.Getenv(name + "_FILE"))
+			if path != "" {
+				data, err := os.ReadFile(path)
+				if err != nil {
+					return fmt.Errorf("%s_FILE: %v", name, err)
+				}
+				if raw = strings.TrimSpace(string(data)); raw == "" {
+					return fmt.Errorf("%s_FILE: %s is empty", name, path)
+				}
+				// Lists may be written one item per line
+				if value.Kind() == reflect.Slice {
+					raw = strings.ReplaceAll(raw, "\n", ",")
+				}
+			}
+		}
+		if raw == "" {
+			return nil
+		}
+		if err := parseInto(value, raw); err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+		return nil
+	})
+}
+
+// mask hides the value of every field tagged secret.
+func mask(v reflect.Value) {
+	walk(v, "", func(key string, field reflect.StructField, value reflect.Value) error {
+		if field.Tag.Get("secret") != "true" {
+			return nil
+		}
+		switch value.Kind() {
+		case reflect.String:
+			if value.String() != "" {
+				value.SetString("********")
+			}
+		case reflect.Slice:
+			// A new slice, the masked config shares its backing array with the real one
+			masked := make([]string, value.Len())
+			for i := range masked {
+				masked[i] = "********"
+			}
+			value.Set(reflect.ValueOf(masked))
+		}
+		return nil
+	})
+}
+
+// walk calls fn for every leaf field of the struct v, descending into nested
+// config structs. key is the field's dotted path in the file, e.g.
+// "telegram.workers".
+func walk(v reflect.Value, prefix string, fn func(key string, field reflect.StructField, value reflect.Value) error) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field, value := t.Field(i), v.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		key := prefix + name
+		if field.Type.Kind() == reflect.Struct {
+			if err := walk(value, key+".", fn); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := fn(key, field, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseInto sets value from its text form. Lists are comma-separated.
+func parseInto(value reflect.Value, raw string) error {
+	if value.Kind() == reflect.Slice {
+		parts := strings.Split(raw, ",")
+		list := reflect.MakeSlice(value.Type(), 0, len(parts))
+		for _, part := range parts {
+			if part = strings.TrimSpace(part); part == "" {
+				continue
+			}
+			item := reflect.New(value.Type().Elem()).Elem()
+			if err := parseInto(item, part); err != nil {
+				return err
+			}
+			list = reflect.Append(list, item)
+		}
+		value.Set(list)
+		return nil
+	}
+
+	if value.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q, expected e.g. 30s or 5m", raw)
+		}
+		value.SetInt(int64(d))
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q, expected true or false", raw)
+		}
+		value.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		value.SetInt(n)
+	default:
+		return fmt.Errorf("unsupported setting type %s", value.Type())
+	}
+	return nil
+}
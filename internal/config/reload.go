@@ -0,0 +1,150 @@
+package config
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"reflect"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// How often the config file is checked for changes
+const watchInterval = 5 * time.Second
+
+// Watcher reloads the config on SIGHUP or when the config file changes.
+// Settings tagged reload are handed to subscribers; changes to the others
+// need a restart, so they are logged and ignored.
+type Watcher struct {
+	mu          sync.Mutex
+	current     *Config
+	modTime     time.Time
+	subscribers []func(cfg *Config)
+}
+
+// NewWatcher watches the file and profile cfg was loaded from.
+func NewWatcher(cfg *Config) *Watcher {
+	w := &Watcher{current: cfg}
+	w.modTime = w.fileModTime()
+	return w
+}
+
+// Subscribe registers fn to be called with the new config after every reload
+// that changed a reloadable setting. fn runs on the watcher's goroutine.
+func (w *Watcher) Subscribe(fn func(cfg *Config)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.subscribers = append(w.subscribers, fn)
+}
+
+// Current returns the config in effect.
+func (w *Watcher) Current() *Config {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.current
+}
+
+// Run reloads on SIGHUP and on file changes until ctx is done.
+func (w *Watcher) Run(ctx context.Context) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangup:
+			logger.Info("reloading config on SIGHUP")
+			w.Reload()
+		case <-ticker.C:
+			if modTime := w.fileModTime(); !modTime.Equal(w.modTime) {
+				w.modTime = modTime
+				logger.Info("config file changed, reloading", "file", w.Current().File)
+				w.Reload()
+			}
+		}
+	}
+}
+
+func (w *Watcher) fileModTime() time.Time {
+	path := w.Current().File
+	if path == "" {
+		return time.Time{}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// Reload reads the config again and applies what can change live. A config
+// that doesn't load or validate is rejected as a whole.
+func (w *Watcher) Reload() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	loaded, err := Load(w.current.File, w.current.Profile)
+	if err == nil {
+		err = loaded.Validate()
+	}
+	if err != nil {
+		logger.Error("config reload failed, keeping the current config", "error", err)
+		return
+	}
+
+	// Start from what's running and take over only the reloadable settings
+	next := *w.current
+	nextValues := leaves(&next)
+	loadedValues := leaves(loaded)
+	keys := make([]string, 0, len(loadedValues))
+	for key := range loadedValues {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var applied []string
+	for _, key := range keys {
+		current, value := nextValues[key], loadedValues[key]
+		if reflect.DeepEqual(current.value.Interface(), value.value.Interface()) {
+			continue
+		}
+		if value.field.Tag.Get("reload") != "true" {
+			logger.Warn("config change needs a restart, ignoring it", "key", key)
+			continue
+		}
+		current.value.Set(value.value)
+		applied = append(applied, key)
+	}
+	if len(applied) == 0 {
+		logger.Info("config reloaded, nothing to apply")
+		return
+	}
+
+	w.current = &next
+	logger.Info("config reloaded", "applied", applied)
+	for _, fn := range w.subscribers {
+		fn(w.current)
+	}
+}
+
+type leaf struct {
+	field reflect.StructField
+	value reflect.Value
+}
+
+// leaves returns every setting of cfg by key, settable in place.
+func leaves(cfg *Config) map[string]leaf {
+	values := make(map[string]leaf)
+	walk(reflect.ValueOf(cfg).Elem(), "", func(key string, field reflect.StructField, value reflect.Value) error {
+		values[key] = leaf{field: field, value: value}
+		return nil
+	})
+	return values
+}
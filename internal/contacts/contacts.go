@@ -0,0 +1,217 @@
+package contacts
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"virtual-assistant/internal/logging"
+	"virtual-assistant/internal/storage"
+)
+
+var logger = logging.For("contacts")
+
+type Contact struct {
+	Name    string   `json:"name"`
+	Email   string   `json:"email"`
+	Aliases []string `json:"aliases,omitempty"`
+	Learned bool     `json:"learned,omitempty"` // Picked up from event attendees rather than imported
+}
+
+// Group is a named set of contact emails, e.g. "backend team".
+type Group struct {
+	Name    string   `json:"name"`
+	Members []string `json:"members"`
+}
+
+type bookData struct {
+	Contacts []*Contact `json:"contacts"`
+	Groups   []*Group   `json:"groups"`
+}
+
+// Book is the contact book shared by the assistant, kept in the database
+// with a copy in memory for reads.
+type Book struct {
+	db    *storage.DB
+	mutex sync.RWMutex
+	data  bookData
+}
+
+func NewBook(db *storage.DB) (*Book, error) {
+	book := &Book{db: db}
+
+	data, err := db.ContactBook()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read contacts: %v", err)
+	}
+	if data == nil {
+		return book, nil
+	}
+
+	if err := json.Unmarshal(data, &book.data); err != nil {
+		return nil, fmt.Errorf("failed to parse contacts: %v", err)
+	}
+
+	return book, nil
+}
+
+// Add inserts or updates a contact by email. Imported data always wins over
+// learned data, but a learned name never overwrites an existing one.
+func (b *Book) Add(contact Contact) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.upsert(contact)
+	return b.save()
+}
+
+// AddAll is Add for many contacts with a single write.
+func (b *Book) AddAll(contacts []Contact) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for _, contact := range contacts {
+		b.upsert(contact)
+	}
+	return b.save()
+}
+
+func (b *Book) upsert(contact Contact) {
+	contact.Email = strings.ToLower(strings.TrimSpace(contact.Email))
+	contact.Name = strings.TrimSpace(contact.Name)
+	if contact.Email == "" {
+		return
+	}
+
+	for _, existing := range b.data.Contacts {
+		if existing.Email != contact.Email {
+			continue
+		}
+		if contact.Learned {
+			if existing.Name == "" {
+				existing.Name = contact.Name
+			}
+			return
+		}
+		if contact.Name != "" {
+			existing.Name = contact.Name
+		}
+		existing.Aliases = mergeStrings(existing.Aliases, contact.Aliases)
+		existing.Learned = false
+		return
+	}
+
+	if contact.Name == "" {
+		contact.Name = strings.Split(contact.Email, "@")[0]
+	}
+	c := contact
+	b.data.Contacts = append(b.data.Contacts, &c)
+}
+
+// AddAlias attaches an extra name to the contact with the given email.
+func (b *Book) AddAlias(email, alias string) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	email = strings.ToLower(strings.TrimSpace(email))
+	for _, existing := range b.data.Contacts {
+		if existing.Email == email {
+			existing.Aliases = mergeStrings(existing.Aliases, []string{alias})
+			return b.save()
+		}
+	}
+	return fmt.Errorf("no contact with email %s", email)
+}
+
+// SetGroup creates or replaces a group.
+func (b *Book) SetGroup(name string, members []string) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.setGroup(name, members)
+	return b.save()
+}
+
+func (b *Book) setGroup(name string, members []string) {
+	var emails []string
+	for _, member := range members {
+		if member = strings.ToLower(strings.TrimSpace(member)); member != "" {
+			emails = append(emails, member)
+		}
+	}
+
+	for _, group := range b.data.Groups {
+		if strings.EqualFold(group.Name, name) {
+			group.Members = emails
+			return
+		}
+	}
+	b.data.Groups = append(b.data.Groups, &Group{Name: strings.TrimSpace(name), Members: emails})
+}
+
+func (b *Book) Contacts() []Contact {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	var contacts []Contact
+	for _, contact := range b.data.Contacts {
+		contacts = append(contacts, *contact)
+	}
+	sort.Slice(contacts, func(i, j int) bool {
+		return strings.ToLower(contacts[i].Name) < strings.ToLower(contacts[j].Name)
+	})
+	return contacts
+}
+
+func (b *Book) Groups() []Group {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	var groups []Group
+	for _, group := range b.data.Groups {
+		groups = append(groups, Group{Name: group.Name, Members: append([]string{}, group.Members...)})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return strings.ToLower(groups[i].Name) < strings.ToLower(groups[j].Name)
+	})
+	return groups
+}
+
+// Flush writes the contact book to the database. Changes are saved as they're made, so this
+// only matters if an earlier save failed; it's called on shutdown.
+func (b *Book) Flush() error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.save()
+}
+
+func (b *Book) save() error {
+	data, err := json.Marshal(b.data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal contacts: %v", err)
+	}
+
+	if err := b.db.SaveContactBook(data); err != nil {
+		return fmt.Errorf("failed to save contacts: %v", err)
+	}
+
+	logger.Debug("saved contacts", "contacts", len(b.data.Contacts), "groups", len(b.data.Groups))
+	return nil
+}
+
+func mergeStrings(existing, extra []string) []string {
+	seen := make(map[string]bool)
+	for _, value := range existing {
+		seen[strings.ToLower(value)] = true
+	}
+	for _, value := range extra {
+		value = strings.TrimSpace(value)
+		if value != "" && !seen[strings.ToLower(value)] {
+			existing = append(existing, value)
+			seen[strings.ToLower(value)] = true
+		}
+	}
+	return existing
+}
@@ -0,0 +1,208 @@
+package contacts
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type ImportResult struct {
+	Contacts int
+	Groups   int
+}
+
+// Import reads a CSV or vCard file and merges it into the book. The format is
+// picked from the file name, falling back to sniffing the content.
+func (b *Book) Import(fileName string, r io.Reader) (*ImportResult, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read contacts file: %v", err)
+	}
+
+	var contacts []Contact
+	var groups map[string][]string
+
+	lowerName := strings.ToLower(fileName)
+	if strings.HasSuffix(lowerName, ".vcf") || strings.HasSuffix(lowerName, ".vcard") || strings.Contains(string(data), "BEGIN:VCARD") {
+		contacts, groups = parseVCard(string(data))
+	} else {
+		contacts, groups, err = parseCSV(string(data))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(contacts) == 0 {
+		return nil, fmt.Errorf("no contacts with an email address found")
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for _, contact := range contacts {
+		b.upsert(contact)
+	}
+	for name, members := range groups {
+		existing := []string{}
+		for _, group := range b.data.Groups {
+			if strings.EqualFold(group.Name, name) {
+				existing = group.Members
+			}
+		}
+		b.setGroup(name, mergeStrings(append([]string{}, existing...), members))
+	}
+
+	if err := b.save(); err != nil {
+		return nil, err
+	}
+
+	return &ImportResult{Contacts: len(contacts), Groups: len(groups)}, nil
+}
+
+// parseCSV accepts either a headerless name,email[,aliases[,groups]] layout or
+// a header row naming those columns (Google/Outlook exports work too). Aliases
+// and groups are separated by ";".
+func parseCSV(data string) ([]Contact, map[string][]string, error) {
+	reader := csv.NewReader(strings.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid CSV: %v", err)
+	}
+	if len(records) == 0 {
+		return nil, nil, nil
+	}
+
+	nameCol, emailCol, aliasCol, groupCol := 0, 1, 2, 3
+	if header := records[0]; !strings.Contains(strings.Join(header, ","), "@") {
+		nameCol, emailCol, aliasCol, groupCol = -1, -1, -1, -1
+		for i, heading := range header {
+			heading = strings.ToLower(strings.TrimSpace(heading))
+			isEmail := strings.HasPrefix(heading, "e-mail") || strings.HasPrefix(heading, "email")
+			switch {
+			case emailCol < 0 && isEmail && !strings.Contains(heading, "type") && !strings.Contains(heading, "label"):
+				emailCol = i
+			case nameCol < 0 && (heading == "name" || heading == "full name" || heading == "display name"):
+				nameCol = i
+			case aliasCol < 0 && (heading == "aliases" || heading == "alias" || heading == "nickname"):
+				aliasCol = i
+			case groupCol < 0 && (heading == "groups" || heading == "group" || heading == "group membership"):
+				groupCol = i
+			}
+		}
+		if emailCol < 0 {
+			return nil, nil, fmt.Errorf("CSV header has no email column")
+		}
+		records = records[1:]
+	}
+
+	var contacts []Contact
+	groups := make(map[string][]string)
+	for _, record := range records {
+		email := column(record, emailCol)
+		if !strings.Contains(email, "@") {
+			continue
+		}
+		contacts = append(contacts, Contact{
+			Name:    column(record, nameCol),
+			Email:   email,
+			Aliases: splitList(column(record, aliasCol)),
+		})
+		// Google separates group memberships with ":::"
+		for _, group := range splitList(strings.ReplaceAll(column(record, groupCol), ":::", ";")) {
+			// Google exports tag everyone with "* myContacts"
+			if strings.HasPrefix(group, "*") {
+				continue
+			}
+			groups[group] = append(groups[group], email)
+		}
+	}
+
+	return contacts, groups, nil
+}
+
+// parseVCard reads FN, EMAIL, NICKNAME and CATEGORIES from vCard 3/4 files.
+func parseVCard(data string) ([]Contact, map[string][]string) {
+	var contacts []Contact
+	groups := make(map[string][]string)
+
+	var current *Contact
+	var categories []string
+
+	for _, line := range unfoldVCard(data) {
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		// Drop parameters such as EMAIL;TYPE=work
+		name := strings.ToUpper(strings.SplitN(key, ";", 2)[0])
+		if dot := strings.LastIndex(name, "."); dot >= 0 {
+			name = name[dot+1:] // Apple's item1.EMAIL grouping
+		}
+
+		switch name {
+		case "BEGIN":
+			current = &Contact{}
+			categories = nil
+		case "FN":
+			if current != nil {
+				current.Name = strings.TrimSpace(value)
+			}
+		case "EMAIL":
+			if current != nil && current.Email == "" {
+				current.Email = strings.TrimSpace(value)
+			}
+		case "NICKNAME":
+			if current != nil {
+				current.Aliases = append(current.Aliases, splitList(strings.ReplaceAll(value, ",", ";"))...)
+			}
+		case "CATEGORIES":
+			categories = append(categories, splitList(strings.ReplaceAll(value, ",", ";"))...)
+		case "END":
+			if current != nil && current.Email != "" {
+				contacts = append(contacts, *current)
+				for _, category := range categories {
+					groups[category] = append(groups[category], current.Email)
+				}
+			}
+			current = nil
+		}
+	}
+
+	return contacts, groups
+}
+
+func unfoldVCard(data string) []string {
+	var lines []string
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func column(record []string, index int) string {
+	if index < 0 || index >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[index])
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ";") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
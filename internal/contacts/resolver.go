@@ -0,0 +1,113 @@
+package contacts
+
+import (
+	"strings"
+)
+
+// Match is the outcome of resolving one name. Exactly one of these holds:
+// Emails is set (resolved, possibly a whole group), Candidates has more than
+// one entry (ambiguous), or both are empty (unknown).
+type Match struct {
+	Query      string
+	Emails     []string
+	Candidates []Contact
+}
+
+func (m *Match) Resolved() bool  { return len(m.Emails) > 0 }
+func (m *Match) Ambiguous() bool { return len(m.Candidates) > 1 }
+
+// Resolve turns names, aliases, group names or plain email addresses into
+// attendee emails.
+func (b *Book) Resolve(query string) Match {
+	query = strings.TrimSpace(query)
+	match := Match{Query: query}
+
+	if strings.Contains(query, "@") {
+		match.Emails = []string{strings.ToLower(query)}
+		return match
+	}
+
+	normalized := normalizeName(query)
+	if normalized == "" {
+		return match
+	}
+
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	for _, group := range b.data.Groups {
+		groupName := normalizeName(group.Name)
+		if groupName == normalized || groupName+" team" == normalized || groupName == normalized+" team" {
+			match.Emails = append([]string{}, group.Members...)
+			return match
+		}
+	}
+
+	// Strongest signal first: full name or alias, then a single name token,
+	// then a prefix ("Rin" -> "Rina")
+	matchers := []func(c *Contact) bool{
+		func(c *Contact) bool {
+			if normalizeName(c.Name) == normalized {
+				return true
+			}
+			for _, alias := range c.Aliases {
+				if normalizeName(alias) == normalized {
+					return true
+				}
+			}
+			return false
+		},
+		func(c *Contact) bool {
+			for _, token := range strings.Fields(normalizeName(c.Name)) {
+				if token == normalized {
+					return true
+				}
+			}
+			return false
+		},
+		func(c *Contact) bool {
+			return strings.HasPrefix(normalizeName(c.Name), normalized)
+		},
+	}
+
+	for _, matches := range matchers {
+		var candidates []Contact
+		for _, contact := range b.data.Contacts {
+			if matches(contact) {
+				candidates = append(candidates, *contact)
+			}
+		}
+		if len(candidates) == 1 {
+			match.Emails = []string{candidates[0].Email}
+			return match
+		}
+		if len(candidates) > 1 {
+			match.Candidates = candidates
+			return match
+		}
+	}
+
+	return match
+}
+
+// SplitNames breaks "Rina, Budi and the backend team" into individual names.
+func SplitNames(text string) []string {
+	text = strings.ReplaceAll(text, " and ", ",")
+	text = strings.ReplaceAll(text, " dan ", ",") // Indonesian "and"
+	text = strings.ReplaceAll(text, "&", ",")
+
+	var names []string
+	for _, name := range strings.Split(text, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func normalizeName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = strings.TrimPrefix(name, "the ")
+	name = strings.TrimPrefix(name, "tim ") // Indonesian "team"
+	return strings.Join(strings.Fields(name), " ")
+}
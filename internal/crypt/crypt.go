@@ -0,0 +1,246 @@
+// Package crypt encrypts the files the assistant keeps on disk, such as the
+// Google OAuth token and per-user data, with AES-256-GCM. Files written
+// before a key was configured are still read as plaintext and get encrypted
+// the next time they are saved.
+package crypt
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// KeySize is the length of a decoded key: AES-256.
+const KeySize = 32
+
+// Sealed data is a single line: the prefix, the ID of the key used and the
+// base64 nonce and ciphertext.
+const prefix = "vaenc1:"
+
+// ErrCannotDecrypt is returned for encrypted files none of the keys can open.
+var ErrCannotDecrypt = errors.New("cannot decrypt")
+
+type key struct {
+	id   string
+	aead cipher.AEAD
+}
+
+var (
+	mu      sync.RWMutex
+	current *key            // Encrypts new writes; nil writes plaintext
+	known   map[string]*key // Every key that can decrypt, by ID
+)
+
+// SetKeys sets the key new files are encrypted with and older keys that can
+// still decrypt files written before a rotation. Keys are base64, see
+// GenerateKey. An empty current key leaves new files unencrypted.
+func SetKeys(currentKey string, oldKeys []string) error {
+	keys := make(map[string]*key)
+	var next *key
+	for i, encoded := range append([]string{currentKey}, oldKeys...) {
+		if encoded == "" {
+			continue
+		}
+		k, err := parseKey(encoded)
+		if err != nil {
+			return err
+		}
+		keys[k.id] = k
+		if i == 0 {
+			next = k
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	current, known = next, keys
+	return nil
+}
+
+// ParseKey checks that encoded is a usable key.
+func ParseKey(encoded string) error {
+	_, err := parseKey(encoded)
+	return err
+}
+
+func parseKey(encoded string) (*key, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(raw) != KeySize {
+		return nil, fmt.Errorf("encryption keys must be %d bytes, base64 encoded", KeySize)
+	}
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(raw)
+	return &key{id: hex.EncodeToString(sum[:4]), aead: aead}, nil
+}
+
+// GenerateKey returns a new random key, base64 encoded.
+func GenerateKey() (string, error) {
+	raw := make([]byte, KeySize)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(raw), nil
+}
+
+// ReadFile reads path, decrypting it if it was encrypted.
+func ReadFile(path string) ([]byte, error) {
+	data, _, err := readFile(path)
+	return data, err
+}
+
+// readFile also returns the ID of the key the file was encrypted with, or ""
+// for plaintext.
+func readFile(path string) ([]byte, string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+	plaintext, id, err := open(data)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", path, err)
+	}
+	return plaintext, id, nil
+}
+
+// Seal encrypts data with the current key, or returns it unchanged if there
+// is none.
+func Seal(data []byte) ([]byte, error) {
+	mu.RLock()
+	k := current
+	mu.RUnlock()
+	if k == nil {
+		return data, nil
+	}
+
+	nonce := make([]byte, k.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to encrypt: %v", err)
+	}
+	sealed := k.aead.Seal(nonce, nonce, data, nil)
+	return []byte(prefix + k.id + ":" + base64.StdEncoding.EncodeToString(sealed) + "\n"), nil
+}
+
+// Open decrypts data sealed with any known key. Data that was never sealed is
+// returned unchanged.
+func Open(data []byte) ([]byte, error) {
+	plaintext, _, err := open(data)
+	return plaintext, err
+}
+
+// Sealed reports whether data was sealed with the current key, so rotation
+// can skip it.
+func Sealed(data []byte) bool {
+	id := CurrentKeyID()
+	return id != "" && bytes.HasPrefix(data, []byte(prefix+id+":"))
+}
+
+func open(data []byte) ([]byte, string, error) {
+	if !bytes.HasPrefix(data, []byte(prefix)) {
+		return data, "", nil
+	}
+
+	id, payload, found := strings.Cut(strings.TrimSpace(string(data[len(prefix):])), ":")
+	if !found {
+		return nil, "", fmt.Errorf("malformed encrypted data")
+	}
+
+	mu.RLock()
+	k, ok := known[id]
+	noKeys := len(known) == 0
+	mu.RUnlock()
+	if noKeys {
+		return nil, "", fmt.Errorf("%w: the data is encrypted but no encryption key is configured", ErrCannotDecrypt)
+	}
+	if !ok {
+		return nil, "", fmt.Errorf("%w: encrypted with unknown key %s, add it to the old encryption keys", ErrCannotDecrypt, id)
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil || len(sealed) < k.aead.NonceSize() {
+		return nil, "", fmt.Errorf("malformed encrypted data")
+	}
+	nonce, ciphertext := sealed[:k.aead.NonceSize()], sealed[k.aead.NonceSize():]
+	plaintext, err := k.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: the data is damaged or was altered", ErrCannotDecrypt)
+	}
+	return plaintext, id, nil
+}
+
+// WriteFile writes data to path, encrypted with the current key if there is
+// one. The file is replaced atomically, so a crash never leaves half of it.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	data, err := Seal(data)
+	if err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Rewrite re-encrypts path with the current key, after a rotation or when
+// encryption is first turned on. It returns how the file was stored before:
+// "plaintext" or "key <id>". A missing file returns an error satisfying
+// os.IsNotExist.
+func Rewrite(path string) (string, error) {
+	data, id, err := readFile(path)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if err := WriteFile(path, data, info.Mode().Perm()&0600); err != nil {
+		return "", err
+	}
+	if id == "" {
+		return "plaintext", nil
+	}
+	return "key " + id, nil
+}
+
+// CurrentKeyID returns the ID of the key new files are encrypted with, or ""
+// if they aren't.
+func CurrentKeyID() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	if current == nil {
+		return ""
+	}
+	return current.id
+}
@@ -0,0 +1,523 @@
+// Package dateparse reads dates, times and durations out of short English
+// and Indonesian messages ("besok jam 3 sore", "next friday 10:30 for an
+// hour") without calling the LLM. It only understands what it can be sure
+// of; anything else is left for the model.
+package dateparse
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Result is what Parse understood. Fields are only meaningful when the
+// matching Has* flag is set.
+type Result struct {
+	Date        time.Time // Midnight of the mentioned day, in the caller's location
+	HasDate     bool
+	Hour        int
+	Minute      int
+	HasTime     bool
+	Duration    time.Duration // From "for 2 hours" or a "2-3pm" range
+	HasDuration bool
+	Rest        string // The message with every recognised phrase removed
+
+	// Set when the date or time rests on a guess: a bare "5/6" may not be a
+	// date at all, and "at 10" doesn't say morning or evening.
+	DateGuessed bool
+	TimeGuessed bool
+}
+
+// Start combines the date and time into a single moment. Without a date the
+// next occurrence of the time is used, so "at 9" said in the evening means
+// tomorrow morning.
+func (r Result) Start(now time.Time) (time.Time, bool) {
+	if !r.HasTime {
+		return time.Time{}, false
+	}
+
+	day := r.Date
+	if !r.HasDate {
+		day = midnight(now)
+	}
+	start := time.Date(day.Year(), day.Month(), day.Day(), r.Hour, r.Minute, 0, 0, now.Location())
+	if !r.HasDate && start.Before(now) {
+		start = start.AddDate(0, 0, 1)
+	}
+	return start, true
+}
+
+// Parts of the day that shift a bare hour into the afternoon or night.
+const (
+	partMorning = iota + 1
+	partAfternoon
+	partEvening
+	partNight
+)
+
+var partsOfDay = map[string]int{
+	"pagi":             partMorning,
+	"morning":          partMorning,
+	"in the morning":   partMorning,
+	"siang":            partAfternoon,
+	"afternoon":        partAfternoon,
+	"in the afternoon": partAfternoon,
+	"sore":             partEvening,
+	"evening":          partEvening,
+	"in the evening":   partEvening,
+	"malam":            partNight,
+	"at night":         partNight,
+	"tonight":          partNight,
+}
+
+var (
+	isoDatePattern     = regexp.MustCompile(`(?i)\b(?:on\s+|tanggal\s+|tgl\.?\s+)?(\d{4})-(\d{1,2})-(\d{1,2})\b`)
+	numericDatePattern = regexp.MustCompile(`(?i)\b(?:on\s+|tanggal\s+|tgl\.?\s+)?(\d{1,2})/(\d{1,2})(?:/(\d{2}|\d{4}))?\b`)
+	dayMonthPattern    = regexp.MustCompile(`(?i)\b(?:on\s+(?:the\s+)?|tanggal\s+|tgl\.?\s+)?(\d{1,2})(?:st|nd|rd|th)?\s+(?:of\s+)?(` + alternation(months) + `)\b\.?(?:,?\s+(\d{4})\b)?`)
+	monthDayPattern    = regexp.MustCompile(`(?i)\b(?:on\s+)?(` + alternation(months) + `)\.?\s+(\d{1,2})(?:st|nd|rd|th)?\b(?:,?\s+(\d{4})\b)?`)
+
+	offsetPattern   = regexp.MustCompile(`(?i)\b(?:in\s+(\d+)\s+(minutes?|mins?|hours?|hrs?|days?|weeks?)|(\d+)\s+(menit|jam|hari|minggu)\s+lagi)\b`)
+	weekdayPattern  = regexp.MustCompile(`(?i)\b(?:(on|next|this|coming)\s+)?(hari\s+)?(` + alternation(weekdays) + `)\b(?:\s+(next week|minggu depan|pekan depan|depan|ini))?`)
+	weekPattern     = regexp.MustCompile(`(?i)\b(next week|minggu depan|pekan depan|this weekend|weekend ini|akhir pekan|next month|bulan depan)\b`)
+	relativePattern = regexp.MustCompile(`(?i)\b(` + alternation(relativeDays) + `)\b`)
+
+	durationPattern     = regexp.MustCompile(`(?i)\b(?:for\s+|selama\s+)?(\d+(?:[.,]\d+)?)\s*(` + alternation(durationUnits) + `)\b`)
+	wordDurationPattern = regexp.MustCompile(`(?i)\b(?:for\s+|selama\s+)?(an hour and a half|half an hour|an hour|one hour|satu setengah jam|setengah jam|satu jam|sejam)\b`)
+
+	clock             = `(\d{1,2})(?:[:.](\d{2}))?(?:\s*(am|pm|a\.m\.|p\.m\.))?`
+	timeRangePattern  = regexp.MustCompile(`(?i)\b(?:from\s+|dari\s+)?(at\s+|jam\s+|pukul\s+|@\s*)?` + clock + `\s*(?:-|–|to|until|till|sampai|hingga|s/d)\s*(?:jam\s+|pukul\s+)?` + clock + `(?:\s+(` + alternation(partsOfDay) + `))?\b`)
+	timePattern       = regexp.MustCompile(`(?i)(?:\b(at\s+|jam\s+|pukul\s+)|@\s*|\b)` + clock + `(?:\s+(` + alternation(partsOfDay) + `))?\b`)
+	namedTimePattern  = regexp.MustCompile(`(?i)\b(?:at\s+)?(noon|midday|tengah hari|midnight|tengah malam)\b`)
+	connectorPattern  = regexp.MustCompile(`(?i)^(?:on|at|for|from|pada|jam|pukul|selama|dari|tanggal|,|-)\s+|\s+(?:on|at|for|from|pada|jam|pukul|selama|dari|tanggal|,|-)$`)
+	whitespacePattern = regexp.MustCompile(`\s+`)
+)
+
+// Parse reads the first date, time and duration it can find in text,
+// relative to now. Dates without a year roll over to next year once they
+// have passed.
+func Parse(text string, now time.Time) Result {
+	p := &parser{text: text, now: now, today: midnight(now)}
+
+	p.parseOffset()
+	p.parseDate()
+	p.parseDuration()
+	p.parseTime()
+
+	p.result.Rest = p.rest()
+	return p.result
+}
+
+type parser struct {
+	text    string
+	now     time.Time
+	today   time.Time
+	evening bool // "tonight" / "malam ini" was mentioned
+	result  Result
+}
+
+// take finds the first match of pattern and blanks it out so later passes
+// (and Rest) don't see it again.
+func (p *parser) take(pattern *regexp.Regexp) []string {
+	loc := pattern.FindStringSubmatchIndex(p.text)
+	if loc == nil {
+		return nil
+	}
+	return p.consume(loc)
+}
+
+func (p *parser) consume(loc []int) []string {
+	groups := submatches(p.text, loc)
+	p.text = p.text[:loc[0]] + " " + p.text[loc[1]:]
+	return groups
+}
+
+func (p *parser) setDate(day time.Time) {
+	p.result.Date = midnight(day)
+	p.result.HasDate = true
+}
+
+// parseOffset handles "in 2 hours" and "3 hari lagi".
+func (p *parser) parseOffset() {
+	m := p.take(offsetPattern)
+	if m == nil {
+		return
+	}
+
+	amount, unit := m[1], m[2]
+	if amount == "" {
+		amount, unit = m[3], m[4]
+	}
+	n, _ := strconv.Atoi(amount)
+
+	switch unit = strings.ToLower(unit); {
+	case strings.HasPrefix(unit, "day") || unit == "hari":
+		p.setDate(p.today.AddDate(0, 0, n))
+	case strings.HasPrefix(unit, "week") || unit == "minggu":
+		p.setDate(p.today.AddDate(0, 0, 7*n))
+	case strings.HasPrefix(unit, "min") || unit == "menit":
+		p.setMoment(p.now.Add(time.Duration(n) * time.Minute))
+	default:
+		p.setMoment(p.now.Add(time.Duration(n) * time.Hour))
+	}
+}
+
+func (p *parser) setMoment(t time.Time) {
+	p.setDate(t)
+	p.result.Hour, p.result.Minute = t.Hour(), t.Minute()
+	p.result.HasTime = true
+}
+
+func (p *parser) parseDate() {
+	if p.result.HasDate {
+		return
+	}
+
+	if m := p.take(isoDatePattern); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		month, _ := strconv.Atoi(m[2])
+		day, _ := strconv.Atoi(m[3])
+		p.setCalendarDate(year, month, day)
+		return
+	}
+
+	// Day first, as written in Indonesia
+	if m := p.take(numericDatePattern); m != nil {
+		day, _ := strconv.Atoi(m[1])
+		month, _ := strconv.Atoi(m[2])
+		p.setCalendarDate(parseYear(m[3]), month, day)
+		// Without "on"/"tanggal" or a year it could be a ratio or a room
+		written := strings.TrimSpace(m[0])
+		p.result.DateGuessed = p.result.HasDate && m[3] == "" && written[0] >= '0' && written[0] <= '9'
+		return
+	}
+
+	if m := p.take(dayMonthPattern); m != nil {
+		day, _ := strconv.Atoi(m[1])
+		p.setCalendarDate(parseYear(m[3]), int(months[strings.ToLower(m[2])]), day)
+		return
+	}
+
+	if m := p.take(monthDayPattern); m != nil {
+		day, _ := strconv.Atoi(m[2])
+		p.setCalendarDate(parseYear(m[3]), int(months[strings.ToLower(m[1])]), day)
+		return
+	}
+
+	if p.parseWeekday() {
+		return
+	}
+
+	if m := p.take(weekPattern); m != nil {
+		switch strings.ToLower(m[1]) {
+		case "next week", "minggu depan", "pekan depan":
+			p.setDate(startOfWeek(p.today).AddDate(0, 0, 7))
+		case "next month", "bulan depan":
+			p.setDate(time.Date(p.today.Year(), p.today.Month()+1, 1, 0, 0, 0, 0, p.today.Location()))
+		default:
+			p.setDate(nextWeekday(p.today, time.Saturday, true))
+		}
+		return
+	}
+
+	if m := p.take(relativePattern); m != nil {
+		word := strings.ToLower(m[1])
+		p.setDate(p.today.AddDate(0, 0, relativeDays[word]))
+		p.evening = word == "tonight" || word == "malam ini" || word == "nanti malam"
+	}
+}
+
+// setCalendarDate ignores impossible dates such as 31/02; a missing year
+// (0) means the next time that day comes round.
+func (p *parser) setCalendarDate(year, month, day int) {
+	if month < 1 || month > 12 || day < 1 || day > 31 {
+		return
+	}
+
+	y := year
+	if y == 0 {
+		y = p.today.Year()
+	}
+	date := time.Date(y, time.Month(month), day, 0, 0, 0, 0, p.today.Location())
+	if date.Day() != day {
+		return
+	}
+	if year == 0 && date.Before(p.today) {
+		date = date.AddDate(1, 0, 0)
+	}
+	p.setDate(date)
+}
+
+// parseWeekday handles "friday", "next monday", "senin depan", "hari rabu".
+// "minggu depan" and "minggu ini" mean next/this week, not Sunday, unless
+// written as "hari minggu".
+func (p *parser) parseWeekday() bool {
+	for _, loc := range weekdayPattern.FindAllStringSubmatchIndex(p.text, -1) {
+		word := strings.ToLower(p.text[loc[6]:loc[7]])
+		var prefix, suffix string
+		if loc[2] >= 0 {
+			prefix = strings.ToLower(p.text[loc[2]:loc[3]])
+		}
+		if loc[8] >= 0 {
+			suffix = strings.ToLower(p.text[loc[8]:loc[9]])
+		}
+		if word == "minggu" && loc[4] < 0 && (suffix == "depan" || suffix == "ini") {
+			continue
+		}
+
+		p.consume(loc)
+		weekday := weekdays[word]
+		switch {
+		case prefix == "next" || suffix != "" && suffix != "ini":
+			offset := (int(weekday) + 6) % 7 // Days after Monday
+			p.setDate(startOfWeek(p.today).AddDate(0, 0, 7+offset))
+		default:
+			p.setDate(nextWeekday(p.today, weekday, prefix == "this" || suffix == "ini"))
+		}
+		return true
+	}
+	return false
+}
+
+func (p *parser) parseDuration() {
+	if m := p.take(wordDurationPattern); m != nil {
+		switch strings.ToLower(m[1]) {
+		case "half an hour", "setengah jam":
+			p.setDuration(30 * time.Minute)
+		case "an hour and a half", "satu setengah jam":
+			p.setDuration(90 * time.Minute)
+		default:
+			p.setDuration(time.Hour)
+		}
+		return
+	}
+
+	// "jam 3" is a time of day; "3 jam" is a duration
+	if m := p.take(durationPattern); m != nil {
+		amount, err := strconv.ParseFloat(strings.Replace(m[1], ",", ".", 1), 64)
+		if err == nil && amount > 0 {
+			p.setDuration(time.Duration(amount * float64(durationUnits[strings.ToLower(m[2])])))
+		}
+	}
+}
+
+func (p *parser) setDuration(d time.Duration) {
+	p.result.Duration = d
+	p.result.HasDuration = true
+}
+
+func (p *parser) parseTime() {
+	if p.result.HasTime {
+		return
+	}
+
+	if p.parseTimeRange() {
+		return
+	}
+
+	for _, loc := range timePattern.FindAllStringSubmatchIndex(p.text, -1) {
+		groups := submatches(p.text, loc)
+		prefix, hour, minute, meridiem, part := groups[1], groups[2], groups[3], groups[4], groups[5]
+
+		// A bare number is only a time when something says so
+		if strings.TrimSpace(prefix) == "" && minute == "" && meridiem == "" && part == "" && !strings.HasPrefix(strings.TrimSpace(groups[0]), "@") {
+			continue
+		}
+
+		h, ok := clockHour(hour, minute, meridiem, part, p.evening)
+		if !ok {
+			continue
+		}
+		p.consume(loc)
+		p.result.Hour, p.result.Minute = h, atoi(minute)
+		p.result.HasTime = true
+		p.result.TimeGuessed = p.guessedHour(hour, meridiem, part)
+		return
+	}
+
+	if m := p.take(namedTimePattern); m != nil {
+		switch strings.ToLower(m[1]) {
+		case "midnight", "tengah malam":
+			p.result.Hour = 0
+		default:
+			p.result.Hour = 12
+		}
+		p.result.HasTime = true
+	}
+}
+
+// parseTimeRange handles "2-3pm", "14:00-15:30" and "jam 9 sampai 11 pagi".
+// A meridiem after the second time applies to both ends.
+func (p *parser) parseTimeRange() bool {
+	for _, loc := range timeRangePattern.FindAllStringSubmatchIndex(p.text, -1) {
+		g := submatches(p.text, loc)
+		prefix := strings.TrimSpace(g[1])
+		startHour, startMinute, startMeridiem := g[2], g[3], g[4]
+		endHour, endMinute, endMeridiem, part := g[5], g[6], g[7], g[8]
+
+		if prefix == "" && startMinute == "" && endMinute == "" && startMeridiem == "" && endMeridiem == "" && part == "" {
+			continue
+		}
+
+		end, ok := clockHour(endHour, endMinute, endMeridiem, part, p.evening)
+		if !ok {
+			continue
+		}
+		endAt := end*60 + atoi(endMinute)
+
+		startMeridiemOrEnd := startMeridiem
+		if startMeridiemOrEnd == "" {
+			startMeridiemOrEnd = endMeridiem
+		}
+		start, ok := clockHour(startHour, startMinute, startMeridiemOrEnd, part, p.evening)
+		if ok && start*60+atoi(startMinute) >= endAt && startMeridiem == "" {
+			// "11-1pm" starts in the morning
+			start, ok = clockHour(startHour, startMinute, "", "", false)
+		}
+		startAt := start*60 + atoi(startMinute)
+		if !ok || startAt >= endAt {
+			continue
+		}
+
+		p.consume(loc)
+		p.result.Hour, p.result.Minute = start, atoi(startMinute)
+		p.result.HasTime = true
+		p.result.TimeGuessed = p.guessedHour(startHour, startMeridiemOrEnd, part)
+		p.setDuration(time.Duration(endAt-startAt) * time.Minute)
+		return true
+	}
+	return false
+}
+
+// guessedHour reports whether clockHour had to guess between morning and
+// evening: a 1-12 hour with no am/pm, part of the day or "tonight". "09:00"
+// and "14:00" are read as the 24-hour clock.
+func (p *parser) guessedHour(hour, meridiem, part string) bool {
+	h := atoi(hour)
+	return meridiem == "" && part == "" && !p.evening && h >= 1 && h <= 12 && !strings.HasPrefix(hour, "0")
+}
+
+// clockHour turns an hour and its qualifiers into 0-23. Without any
+// qualifier, 1-6 are taken as afternoon: nobody books a 3am meeting.
+func clockHour(hour, minute, meridiem, part string, evening bool) (int, bool) {
+	h := atoi(hour)
+	m := atoi(minute)
+	if h > 23 || m > 59 {
+		return 0, false
+	}
+
+	meridiem = strings.ToLower(strings.ReplaceAll(meridiem, ".", ""))
+	if meridiem != "" {
+		if h < 1 || h > 12 {
+			return 0, false
+		}
+		if meridiem == "pm" && h < 12 {
+			h += 12
+		} else if meridiem == "am" && h == 12 {
+			h = 0
+		}
+		return h, true
+	}
+
+	partOfDay := partsOfDay[strings.ToLower(part)]
+	if partOfDay == 0 && evening {
+		partOfDay = partNight
+	}
+
+	switch partOfDay {
+	case partMorning:
+		if h == 12 {
+			h = 0
+		}
+	case partAfternoon:
+		if h >= 1 && h <= 5 {
+			h += 12
+		}
+	case partEvening:
+		if h < 12 {
+			h += 12
+		}
+	case partNight:
+		if h == 12 {
+			h = 0
+		} else if h >= 6 && h < 12 {
+			h += 12
+		}
+	default:
+		if h >= 1 && h <= 6 && minute == "" {
+			h += 12
+		}
+	}
+	return h, true
+}
+
+// rest tidies what's left of the message once dates and times are removed.
+func (p *parser) rest() string {
+	rest := strings.TrimSpace(whitespacePattern.ReplaceAllString(p.text, " "))
+	for {
+		trimmed := strings.TrimSpace(connectorPattern.ReplaceAllString(rest, ""))
+		trimmed = strings.Trim(trimmed, " ,.-")
+		if trimmed == rest {
+			return rest
+		}
+		rest = trimmed
+	}
+}
+
+func submatches(text string, loc []int) []string {
+	groups := make([]string, len(loc)/2)
+	for i := range groups {
+		if loc[2*i] >= 0 {
+			groups[i] = text[loc[2*i]:loc[2*i+1]]
+		}
+	}
+	return groups
+}
+
+func parseYear(s string) int {
+	year := atoi(s)
+	if year > 0 && year < 100 {
+		year += 2000
+	}
+	return year
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+func midnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func startOfWeek(day time.Time) time.Time {
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
+// nextWeekday returns the next given weekday after day, or day itself when
+// it already is that weekday and includeToday is set.
+func nextWeekday(day time.Time, weekday time.Weekday, includeToday bool) time.Time {
+	ahead := (int(weekday) - int(day.Weekday()) + 7) % 7
+	if ahead == 0 && !includeToday {
+		ahead = 7
+	}
+	return day.AddDate(0, 0, ahead)
+}
+
+// alternation builds a regexp alternation from a vocabulary, longest words
+// first so "maret" wins over "mar".
+func alternation[V any](vocabulary map[string]V) string {
+	words := make([]string, 0, len(vocabulary))
+	for word := range vocabulary {
+		words = append(words, regexp.QuoteMeta(word))
+	}
+	sort.Slice(words, func(i, j int) bool {
+		if len(words[i]) != len(words[j]) {
+			return len(words[i]) > len(words[j])
+		}
+		return words[i] < words[j]
+	})
+	return strings.Join(words, "|")
+}
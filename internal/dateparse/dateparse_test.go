@@ -0,0 +1,98 @@
+package dateparse
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	wib := time.FixedZone("WIB", 7*60*60)
+	now := time.Date(2026, time.October, 18, 20, 0, 0, 0, wib) // A Sunday evening
+
+	tests := []struct {
+		text        string
+		date        string // YYYY-MM-DD, empty for none
+		clock       string // HH:MM, empty for none
+		duration    time.Duration
+		dateGuessed bool
+		timeGuessed bool
+	}{
+		// Explicit times
+		{text: "besok jam 3 sore", date: "2026-10-19", clock: "15:00"},
+		{text: "nanti malam jam 8", date: "2026-10-18", clock: "20:00"},
+		{text: "tonight at 8", date: "2026-10-18", clock: "20:00"},
+		{text: "call at 14:00", clock: "14:00"},
+		{text: "standup 09:30", clock: "09:30"},
+		{text: "lunch at noon", clock: "12:00"},
+		{text: "2-3pm", clock: "14:00", duration: time.Hour},
+		{text: "jam 9 sampai 11 pagi", clock: "09:00", duration: 2 * time.Hour},
+		{text: "in 2 hours", date: "2026-10-18", clock: "22:00"},
+
+		// Hours that don't say morning or evening
+		{text: "meeting at 10", clock: "10:00", timeGuessed: true},
+		{text: "sync at 3", clock: "15:00", timeGuessed: true},
+		{text: "room 3 at 10", clock: "10:00", timeGuessed: true},
+		{text: "jam 8", clock: "08:00", timeGuessed: true},
+
+		// Bare numbers aren't times
+		{text: "room 3"},
+		{text: "call 2 people"},
+
+		// Dates
+		{text: "ratio 5/6", date: "2027-06-05", dateGuessed: true},
+		{text: "on 5/6", date: "2027-06-05"},
+		{text: "tanggal 20/10", date: "2026-10-20"},
+		{text: "5/6/2027", date: "2027-06-05"},
+		{text: "2026-11-02", date: "2026-11-02"},
+		{text: "15 December", date: "2026-12-15"},
+		{text: "March 3rd", date: "2027-03-03"},
+		{text: "31/02", date: ""},
+		{text: "tomorrow", date: "2026-10-19"},
+		{text: "lusa", date: "2026-10-20"},
+		{text: "friday", date: "2026-10-23"},
+		{text: "minggu depan", date: "2026-10-19"},
+
+		// Durations
+		{text: "for 2 hours", duration: 2 * time.Hour},
+		{text: "3 jam", duration: 3 * time.Hour},
+		{text: "setengah jam", duration: 30 * time.Minute},
+		{text: "an hour and a half", duration: 90 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got := Parse(tt.text, now)
+
+			date := ""
+			if got.HasDate {
+				date = got.Date.Format("2006-01-02")
+			}
+			if date != tt.date {
+				t.Errorf("date = %q, want %q", date, tt.date)
+			}
+
+			clock := ""
+			if got.HasTime {
+				clock = time.Date(2000, 1, 1, got.Hour, got.Minute, 0, 0, time.UTC).Format("15:04")
+			}
+			if clock != tt.clock {
+				t.Errorf("time = %q, want %q", clock, tt.clock)
+			}
+
+			var duration time.Duration
+			if got.HasDuration {
+				duration = got.Duration
+			}
+			if duration != tt.duration {
+				t.Errorf("duration = %s, want %s", duration, tt.duration)
+			}
+
+			if got.DateGuessed != tt.dateGuessed {
+				t.Errorf("DateGuessed = %t, want %t", got.DateGuessed, tt.dateGuessed)
+			}
+			if got.TimeGuessed != tt.timeGuessed {
+				t.Errorf("TimeGuessed = %t, want %t", got.TimeGuessed, tt.timeGuessed)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package dateparse
+
+import "time"
+
+// Weekday names in English and Indonesian, including common short forms.
+// Bare "minggu" is Sunday; "minggu depan" (next week) is matched first.
+var weekdays = map[string]time.Weekday{
+	"monday":    time.Monday,
+	"mon":       time.Monday,
+	"tuesday":   time.Tuesday,
+	"tue":       time.Tuesday,
+	"tues":      time.Tuesday,
+	"wednesday": time.Wednesday,
+	"wed":       time.Wednesday,
+	"thursday":  time.Thursday,
+	"thu":       time.Thursday,
+	"thurs":     time.Thursday,
+	"friday":    time.Friday,
+	"fri":       time.Friday,
+	"saturday":  time.Saturday,
+	"sat":       time.Saturday,
+	"sunday":    time.Sunday,
+	"sun":       time.Sunday,
+	"senin":     time.Monday,
+	"selasa":    time.Tuesday,
+	"rabu":      time.Wednesday,
+	"kamis":     time.Thursday,
+	"jumat":     time.Friday,
+	"jum'at":    time.Friday,
+	"sabtu":     time.Saturday,
+	"minggu":    time.Sunday,
+	"ahad":      time.Sunday,
+}
+
+var months = map[string]time.Month{
+	"january":   time.January,
+	"januari":   time.January,
+	"jan":       time.January,
+	"february":  time.February,
+	"februari":  time.February,
+	"feb":       time.February,
+	"march":     time.March,
+	"maret":     time.March,
+	"mar":       time.March,
+	"april":     time.April,
+	"apr":       time.April,
+	"may":       time.May,
+	"mei":       time.May,
+	"june":      time.June,
+	"juni":      time.June,
+	"jun":       time.June,
+	"july":      time.July,
+	"juli":      time.July,
+	"jul":       time.July,
+	"august":    time.August,
+	"agustus":   time.August,
+	"aug":       time.August,
+	"agu":       time.August,
+	"agt":       time.August,
+	"september": time.September,
+	"sep":       time.September,
+	"sept":      time.September,
+	"october":   time.October,
+	"oktober":   time.October,
+	"oct":       time.October,
+	"okt":       time.October,
+	"november":  time.November,
+	"nov":       time.November,
+	"december":  time.December,
+	"desember":  time.December,
+	"dec":       time.December,
+	"des":       time.December,
+}
+
+// Words that name a day relative to today.
+var relativeDays = map[string]int{
+	"today":                  0,
+	"tonight":                0,
+	"hari ini":               0,
+	"malam ini":              0,
+	"nanti":                  0,
+	"nanti malam":            0,
+	"tomorrow":               1,
+	"besok":                  1,
+	"esok":                   1,
+	"day after tomorrow":     2,
+	"the day after tomorrow": 2,
+	"lusa":                   2,
+	"yesterday":              -1,
+	"kemarin":                -1,
+}
+
+// Units accepted in durations and "in N ..." offsets.
+var durationUnits = map[string]time.Duration{
+	"minute":  time.Minute,
+	"minutes": time.Minute,
+	"min":     time.Minute,
+	"mins":    time.Minute,
+	"menit":   time.Minute,
+	"hour":    time.Hour,
+	"hours":   time.Hour,
+	"hr":      time.Hour,
+	"hrs":     time.Hour,
+	"h":       time.Hour,
+	"jam":     time.Hour,
+}
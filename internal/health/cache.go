@@ -0,0 +1,42 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type cachedKey struct{}
+
+// cacheHit tells run that a result came from the cache, and how old it is.
+type cacheHit struct {
+	hit       bool
+	checkedAt time.Time
+}
+
+// Cached wraps a check that calls an external API, so probing /readyz often
+// doesn't spend quota or get rate limited. The last result, failures
+// included, is reused until ttl has passed.
+func Cached(ttl time.Duration, check CheckFunc) CheckFunc {
+	var (
+		mu        sync.Mutex
+		checkedAt time.Time
+		detail    string
+		err       error
+	)
+	return func(ctx context.Context) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if time.Since(checkedAt) < ttl {
+			if hit, ok := ctx.Value(cachedKey{}).(*cacheHit); ok {
+				hit.hit, hit.checkedAt = true, checkedAt
+			}
+			return detail, err
+		}
+
+		checkedAt = time.Now()
+		detail, err = check(ctx)
+		return detail, err
+	}
+}
@@ -0,0 +1,155 @@
+// Package health serves the liveness and readiness endpoints. Liveness only
+// says the process is up; readiness runs a check per component and reports
+// each one as JSON.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"virtual-assistant/internal/logging"
+)
+
+// checkTimeout bounds a single check, so one hung dependency can't hold up
+// the whole probe.
+const checkTimeout = 5 * time.Second
+
+var logger = logging.For("health")
+
+// CheckFunc checks one component. It returns a short human-readable detail,
+// such as when the component was last seen working, and an error if the
+// component isn't usable.
+type CheckFunc func(ctx context.Context) (string, error)
+
+// Result is how one component is reported by /readyz.
+type Result struct {
+	Status     string    `json:"status"` // "ok" or "fail"
+	Detail     string    `json:"detail,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	CheckedAt  time.Time `json:"checked_at"`
+	DurationMS int64     `json:"duration_ms"`
+	Cached     bool      `json:"cached,omitempty"`
+}
+
+type report struct {
+	Status     string            `json:"status"`
+	Components map[string]Result `json:"components,omitempty"`
+}
+
+// Checker holds the readiness checks.
+type Checker struct {
+	mu       sync.Mutex
+	checks   map[string]CheckFunc
+	draining atomic.Bool
+}
+
+func New() *Checker {
+	return &Checker{checks: make(map[string]CheckFunc)}
+}
+
+// Add registers a readiness check under name.
+func (c *Checker) Add(name string, check CheckFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks[name] = check
+}
+
+// SetDraining makes /readyz fail from now on, so load balancers stop sending
+// traffic while the process shuts down.
+func (c *Checker) SetDraining() {
+	c.draining.Store(true)
+}
+
+// Check runs every check concurrently and returns the results by name.
+func (c *Checker) Check(ctx context.Context) map[string]Result {
+	c.mu.Lock()
+	checks := make(map[string]CheckFunc, len(c.checks))
+	for name, check := range c.checks {
+		checks[name] = check
+	}
+	c.mu.Unlock()
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		results = make(map[string]Result, len(checks))
+	)
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check CheckFunc) {
+			defer wg.Done()
+			result := run(ctx, check)
+			mu.Lock()
+			results[name] = result
+			mu.Unlock()
+		}(name, check)
+	}
+	wg.Wait()
+	return results
+}
+
+func run(ctx context.Context, check CheckFunc) Result {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	cached := &cacheHit{}
+	ctx = context.WithValue(ctx, cachedKey{}, cached)
+
+	start := time.Now()
+	detail, err := check(ctx)
+	result := Result{
+		Status:     "ok",
+		Detail:     detail,
+		CheckedAt:  start,
+		DurationMS: time.Since(start).Milliseconds(),
+	}
+	if cached.hit {
+		result.CheckedAt, result.DurationMS, result.Cached = cached.checkedAt, 0, true
+	}
+	if err != nil {
+		result.Status = "fail"
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// LiveHandler answers 200 for as long as the process can serve HTTP.
+func (c *Checker) LiveHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, report{Status: "ok"})
+}
+
+// ReadyHandler runs the checks and answers 200 if all of them pass, 503
+// otherwise, with each component's result in the body.
+func (c *Checker) ReadyHandler(w http.ResponseWriter, r *http.Request) {
+	if c.draining.Load() {
+		writeJSON(w, http.StatusServiceUnavailable, report{Status: "shutting_down"})
+		return
+	}
+
+	results := c.Check(r.Context())
+	status, code := "ok", http.StatusOK
+	var failed []string
+	for name, result := range results {
+		if result.Status != "ok" {
+			failed = append(failed, name)
+		}
+	}
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		status, code = "fail", http.StatusServiceUnavailable
+		logger.WarnContext(r.Context(), "not ready", "failed", failed)
+	}
+	writeJSON(w, code, report{Status: status, Components: results})
+}
+
+func writeJSON(w http.ResponseWriter, code int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,405 @@
+package i18n
+
+// catalog maps message keys to format strings. English is the reference;
+// every key should exist there.
+var catalog = map[Language]map[string]string{
+	English: {
+		"lang.name":    "English",
+		"lang.current": "🌐 Language: %s\n\nUse /lang id for Bahasa Indonesia or /lang en for English.",
+		"lang.set":     "✅ I'll reply in English from now on.",
+
+		"error.generic": "Sorry, I encountered an error processing your request.",
+		"llm.queued":    "⏳ I'm working on other requests. You're number %d in line, I'll answer shortly.",
+		"llm.busy":      "😅 I'm handling too many requests right now. Please try again in a minute.",
+		"access.denied": "🔒 This assistant is private. If you should have access, ask its owner to add chat ID %d.",
+
+		"start.help": "Hello! I'm your virtual assistant. I can help you:\n" +
+			"• Create calendar events\n" +
+			"• Check today's meetings (/today)\n" +
+			"• Choose which calendars to use (/calendars)\n" +
+			"• Invite people by name (/contacts)\n" +
+			"• See who's coming to your meetings (/rsvp)\n" +
+			"• Send reminders for upcoming meetings\n" +
+			"• General chat (/chat <message>)\n" +
+			"• Switch language (/lang id|en)\n\n" +
+			"Just tell me what you'd like to do!",
+
+		"event.need_info":            "I need more information to create the event. Please provide a title, start time, and end time.",
+		"event.bad_recurrence":       "I couldn't understand how often this event repeats (%v). Could you rephrase it?",
+		"event.bad_dates":            "I couldn't understand the dates for this event (%v). Could you rephrase it?",
+		"event.not_created":          "❌ Okay, I didn't create the event.",
+		"event.created":              "✅ Event created successfully!\n\nTitle: %s\nDescription: %s\nStart: %s\nEnd: %s",
+		"event.created_all_day":      "✅ All-day event created successfully!\n\nTitle: %s\nDescription: %s\nFrom: %s\nUntil: %s",
+		"event.attendees":            "\nAttendees: %s",
+		"event.repeats":              "\nRepeats: %s",
+		"event.time_corrected":       "\n🕐 I went by the date and time in your message, which differed from my first reading. Tell me if that's wrong.",
+		"event.join":                 "\n🎥 Join: %s",
+		"event.meet_pending":         "\n🎥 A Meet link was requested and will appear in the event shortly.",
+		"event.default_title":        "Meeting",
+		"attendees.unknown":          "👤 I don't know who %s is. Use an email address, or add them with /contacts add <name> <email>.",
+		"attendees.which":            "👥 Which %s do you mean?\n",
+		"attendees.pick_number":      "Please reply with a number between 1 and %d, or no to cancel.\n\n%s",
+		"conflict.overlaps":          "⚠️ \"%s\" overlaps with:\n",
+		"conflict.busy":              "• %s is busy (%s)\n",
+		"conflict.unavailable":       "\nℹ️ Couldn't check availability for: %s\n",
+		"conflict.alternatives":      "\n🕐 Nearest free slots:\n",
+		"conflict.choose":            "\nReply with a number to use that slot, yes to book anyway, or no to cancel.",
+		"conflict.confirm":           "\nReply yes to book anyway or no to cancel.",
+		"conflict.pick_number":       "Please pick a number between 1 and %d, reply yes to book anyway, or no to cancel.",
+		"recurrence.every.DAILY":     "Every day",
+		"recurrence.every.WEEKLY":    "Every week",
+		"recurrence.every.MONTHLY":   "Every month",
+		"recurrence.every.YEARLY":    "Every year",
+		"recurrence.every_n.DAILY":   "Every %d days",
+		"recurrence.every_n.WEEKLY":  "Every %d weeks",
+		"recurrence.every_n.MONTHLY": "Every %d months",
+		"recurrence.every_n.YEARLY":  "Every %d years",
+		"recurrence.on":              " on %s",
+		"recurrence.until":           " until %s",
+		"recurrence.count":           " (%s times)",
+
+		"today.none":           "📅 No meetings scheduled for today!",
+		"today.all_day":        "🗓️ All day:\n",
+		"today.day_of":         " (day %d of %d)",
+		"today.header":         "📅 Today's meetings:\n\n",
+		"today.at":             " at %s",
+		"today.online":         "\n   🎥 Online",
+		"button.join":          "🎥 Join",
+		"button.join_numbered": "🎥 Join %d. %s",
+
+		"series.which_event":   "Which event do you mean? Please tell me its title.",
+		"series.bad_date":      "I couldn't understand the date %q.",
+		"series.not_found":     "🔍 I couldn't find an event matching \"%s\".",
+		"series.cancelled_all": "🗑️ Cancelled every occurrence of \"%s\".",
+		"series.cancelled":     "🗑️ Cancelled \"%s\" on %s.",
+		"series.when":          "When should I move it to? Please give me the new start and end time.",
+		"series.moved_all":     "🔁 Every occurrence of \"%s\" now runs %s.",
+		"series.moved":         "📆 Moved \"%s\" to %s %s.",
+		"series.unknown_date":  "an unknown date",
+
+		"series.confirm_cancel":     "🗑️ Cancel \"%s\" on %s? Reply yes or no.",
+		"series.confirm_cancel_all": "🗑️ Cancel every occurrence of \"%s\"? This can't be undone. Reply yes or no.",
+		"series.confirm_move":       "📆 Move \"%s\" from %s to %s %s? Reply yes or no.",
+		"series.confirm_move_all":   "🔁 Move every occurrence of \"%s\" to %s? Reply yes or no.",
+		"series.kept":               "👍 Okay, \"%s\" stays as it is.",
+
+		"calendars.help": "Usage:\n" +
+			"/calendars - list your calendars\n" +
+			"/calendars use 1,3 - include calendars in agendas and reminders\n" +
+			"/calendars default 2 - where new events go\n" +
+			"/calendars rule work 2 - send events mentioning \"work\" to calendar 2\n" +
+			"/calendars rules clear - remove all rules",
+		"calendars.not_found_named": "I couldn't find a calendar called %q.",
+		"calendars.not_found":       "I couldn't find that calendar.",
+		"calendars.using":           "✅ Using %d calendar(s) for agendas and reminders.",
+		"calendars.default_set":     "✅ New events will go to %s.",
+		"calendars.rule_set":        "✅ Events mentioning \"%s\" will go to %s.",
+		"calendars.rules_cleared":   "✅ Calendar rules cleared.",
+		"calendars.header":          "📚 Your calendars:\n\n",
+		"calendars.primary":         " (primary)",
+		"calendars.default":         " ⭐ default",
+		"calendars.rules":           "\n📐 Rules:\n",
+
+		"contacts.help": "Usage:\n" +
+			"/contacts - list contacts and groups\n" +
+			"/contacts add Rina Putri rina@example.com\n" +
+			"/contacts alias rina@example.com Rin\n" +
+			"/contacts group backend budi@example.com, Rina\n" +
+			"/contacts learn - learn names from past meetings\n" +
+			"Send a .csv or .vcf file with the caption /contacts import to import.",
+		"contacts.added":         "✅ Added %s (%s).",
+		"contacts.alias_added":   "✅ %s can now be called %s.",
+		"contacts.unresolved":    "I couldn't resolve: %s. Use email addresses or unambiguous names.",
+		"contacts.group_set":     "✅ Group %s has %d member(s).",
+		"contacts.learned":       "🧠 Learned %d people from the last %d days of meetings.",
+		"contacts.import_hint":   "📎 Send the .csv or .vcf file as a document with the caption /contacts import.",
+		"contacts.too_large":     "❌ That file is too large to import.",
+		"contacts.import_failed": "❌ Import failed: %v",
+		"contacts.imported":      "✅ Imported %d contact(s) and %d group(s).",
+		"contacts.empty":         "📇 Your contact book is empty.\n\n",
+		"contacts.header":        "📇 %d contact(s):\n",
+		"contacts.aka":           " aka %s",
+		"contacts.groups":        "\n👥 Groups:\n",
+
+		"rsvp.none":               "📭 You aren't organising any meetings with guests in the next %d days.",
+		"rsvp.header":             "📬 RSVPs for your upcoming meetings:\n",
+		"rsvp.status.accepted":    "✅ Accepted",
+		"rsvp.status.tentative":   "🤔 Maybe",
+		"rsvp.status.declined":    "❌ Declined",
+		"rsvp.status.needsAction": "⏳ No response",
+		"rsvp.declined_notice":    "❌ %s declined \"%s\" (%s)",
+		"rsvp.comment_notice":     "💬 %s replied to \"%s\" (%s), possibly proposing a new time:\n\n%s",
+
+		"invitation.header":           "📨 Invitation: %s\n\n",
+		"invitation.all_day":          "🗓️ %s (all day)\n",
+		"invitation.from":             "👤 From %s\n",
+		"invitation.online":           "🎥 Online meeting\n",
+		"invitation.answer_in_google": "\n\nPlease answer this one in Google Calendar.",
+		"invitation.unknown":          "Unknown answer",
+		"invitation.failed":           "Sorry, I couldn't update your response.",
+		"invitation.owner_only":       "Only the calendar's owner can answer this.",
+		"invitation.accepted":         "✅ You accepted",
+		"invitation.tentative":        "🤔 You answered maybe",
+		"invitation.declined":         "❌ You declined",
+		"invitation.note_prompt":      "📝 Want to add a note for the organiser? Reply with it now or with /note <text> within the hour, or /skip.",
+		"invitation.note_usage":       "Usage: /note <text for the organiser>",
+		"invitation.no_pending_note":  "There's no recently answered invitation to add a note to.",
+		"invitation.no_note":          "👍 No note added.",
+		"invitation.note_added":       "📝 Added your note to \"%s\".",
+		"button.accept":               "✅ Accept",
+		"button.maybe":                "🤔 Maybe",
+		"button.decline":              "❌ Decline",
+
+		"reminder.heading":          "Meeting Reminder",
+		"reminder.starting_in":      "Starting in %s",
+		"reminder.attendees":        "👥 Attendees: %s\n\n",
+		"reminder.all_day":          "🗓️ Tomorrow (%s): %s",
+		"reminder.lasts":            "\n\n📆 Lasts %d days",
+		"reminder.test_title":       "Test reminder",
+		"reminder.test_description": "Reminders reach this chat. No meeting was scheduled.",
+
+		"admin.help": "🛠 Admin commands:\n" +
+			"/users - list users and their roles\n" +
+			"/ban <id|@username> - ignore a user\n" +
+			"/unban <id|@username>\n" +
+			"/stats - usage, Claude calls and errors\n" +
+			"/reminders - reminders due or sent in the last day\n" +
+			"Owners only:\n" +
+			"/promote <id|@username> - make a user an admin\n" +
+			"/demote <id|@username>\n" +
+			"/broadcast <message> - message every chat\n" +
+			"/loglevel [level | package=level] - show or change log verbosity",
+		"admin.owner_only":         "⛔ Only the bot's owners can use %s.",
+		"admin.admin_only":         "⛔ Only the bot's admins can use %s.",
+		"admin.no_user":            "❌ I don't know %s. Use their numeric ID or @username.",
+		"admin.is_owner":           "❌ %s is an owner; owners are set in the config.",
+		"admin.outranked":          "❌ You can't change the role of %s.",
+		"admin.banned":             "🚫 %s is banned; I'll ignore their messages.",
+		"admin.unbanned":           "✅ %s can use the bot again.",
+		"admin.promoted":           "⭐ %s is now an admin.",
+		"admin.demoted":            "✅ %s is no longer an admin.",
+		"admin.users_header":       "👥 %d user(s):\n",
+		"admin.user_line":          "• %s (%d)%s, last seen %s\n",
+		"admin.user_unseen":        "• %d%s, never seen\n",
+		"admin.stats":              "📊 Since start, %s ago:\n\n👥 Users: %d (%d admin, %d banned)\n💬 Chats: %d\n📨 Messages: %d handled, %d failed, %d rejected\n🤖 Claude: %d calls, %d failed (%d busy), %d offline fallbacks\n⏰ Reminders: %d sent, %d failed",
+		"admin.broadcast_usage":    "Usage: /broadcast <message>",
+		"admin.broadcast_started":  "📢 Sending to %d chat(s), I'll tell you when it's done.",
+		"admin.broadcast_done":     "📢 Sent to %d of %d chat(s).",
+		"admin.reminders_off":      "⏰ Reminders aren't running.",
+		"admin.reminders_none":     "⏰ No reminders due or sent in the last day.",
+		"admin.reminders_upcoming": "⏰ Due in the next day:\n",
+		"admin.reminders_sent":     "✅ Sent in the last day:\n",
+		"admin.reminder_line":      "• %s: %s (starts %s)\n",
+		"admin.loglevel":           "📝 Log level: %s%s\n\nChange it with /loglevel <level> or /loglevel <package>=<level>, using debug, info, warn or error. A config reload resets it.",
+		"admin.loglevel_set":       "✅ Log level now %s.",
+		"admin.loglevel_bad":       "❌ Unknown log level %q; use debug, info, warn or error.",
+		"role.banned":              "banned",
+		"role.admin":               "admin",
+		"role.owner":               "owner",
+
+		"duration.seconds":       "%d seconds",
+		"duration.minutes":       "%d minutes",
+		"duration.hours":         "%d hour",
+		"duration.hours_minutes": "%d hour %d minutes",
+	},
+
+	Indonesian: {
+		"lang.name":    "Bahasa Indonesia",
+		"lang.current": "🌐 Bahasa: %s\n\nGunakan /lang id untuk Bahasa Indonesia atau /lang en untuk English.",
+		"lang.set":     "✅ Mulai sekarang saya akan membalas dalam Bahasa Indonesia.",
+
+		"error.generic": "Maaf, terjadi kesalahan saat memproses permintaan Anda.",
+		"llm.queued":    "⏳ Saya sedang mengerjakan permintaan lain. Anda di antrean nomor %d, sebentar lagi saya jawab.",
+		"llm.busy":      "😅 Saya sedang menangani terlalu banyak permintaan. Silakan coba lagi sebentar lagi.",
+		"access.denied": "🔒 Asisten ini bersifat pribadi. Jika Anda seharusnya punya akses, minta pemiliknya menambahkan chat ID %d.",
+
+		"start.help": "Halo! Saya asisten virtual Anda. Saya bisa membantu:\n" +
+			"• Membuat acara di kalender\n" +
+			"• Melihat rapat hari ini (/today)\n" +
+			"• Memilih kalender yang dipakai (/calendars)\n" +
+			"• Mengundang orang berdasarkan nama (/contacts)\n" +
+			"• Melihat siapa yang akan hadir (/rsvp)\n" +
+			"• Mengirim pengingat sebelum rapat\n" +
+			"• Ngobrol santai (/chat <pesan>)\n" +
+			"• Mengganti bahasa (/lang id|en)\n\n" +
+			"Katakan saja apa yang ingin Anda lakukan!",
+
+		"event.need_info":            "Saya butuh informasi lebih untuk membuat acara. Sebutkan judul, waktu mulai, dan waktu selesai.",
+		"event.bad_recurrence":       "Saya tidak mengerti seberapa sering acara ini berulang (%v). Bisa diulangi dengan kalimat lain?",
+		"event.bad_dates":            "Saya tidak mengerti tanggal acara ini (%v). Bisa diulangi dengan kalimat lain?",
+		"event.not_created":          "❌ Baik, acaranya tidak saya buat.",
+		"event.created":              "✅ Acara berhasil dibuat!\n\nJudul: %s\nDeskripsi: %s\nMulai: %s\nSelesai: %s",
+		"event.created_all_day":      "✅ Acara seharian berhasil dibuat!\n\nJudul: %s\nDeskripsi: %s\nDari: %s\nSampai: %s",
+		"event.attendees":            "\nPeserta: %s",
+		"event.repeats":              "\nBerulang: %s",
+		"event.time_corrected":       "\n🕐 Saya memakai tanggal dan jam dari pesan Anda, yang berbeda dari bacaan pertama saya. Kabari kalau salah.",
+		"event.join":                 "\n🎥 Gabung: %s",
+		"event.meet_pending":         "\n🎥 Link Meet sudah diminta dan akan segera muncul di acara.",
+		"event.default_title":        "Rapat",
+		"attendees.unknown":          "👤 Saya tidak tahu siapa %s. Gunakan alamat email, atau tambahkan dengan /contacts add <nama> <email>.",
+		"attendees.which":            "👥 %s yang mana?\n",
+		"attendees.pick_number":      "Balas dengan angka antara 1 dan %d, atau tidak untuk membatalkan.\n\n%s",
+		"conflict.overlaps":          "⚠️ \"%s\" bentrok dengan:\n",
+		"conflict.busy":              "• %s sedang sibuk (%s)\n",
+		"conflict.unavailable":       "\nℹ️ Tidak bisa memeriksa ketersediaan: %s\n",
+		"conflict.alternatives":      "\n🕐 Slot kosong terdekat:\n",
+		"conflict.choose":            "\nBalas dengan angka untuk memakai slot itu, ya untuk tetap membuat, atau tidak untuk membatalkan.",
+		"conflict.confirm":           "\nBalas ya untuk tetap membuat atau tidak untuk membatalkan.",
+		"conflict.pick_number":       "Pilih angka antara 1 dan %d, balas ya untuk tetap membuat, atau tidak untuk membatalkan.",
+		"recurrence.every.DAILY":     "Setiap hari",
+		"recurrence.every.WEEKLY":    "Setiap minggu",
+		"recurrence.every.MONTHLY":   "Setiap bulan",
+		"recurrence.every.YEARLY":    "Setiap tahun",
+		"recurrence.every_n.DAILY":   "Setiap %d hari",
+		"recurrence.every_n.WEEKLY":  "Setiap %d minggu",
+		"recurrence.every_n.MONTHLY": "Setiap %d bulan",
+		"recurrence.every_n.YEARLY":  "Setiap %d tahun",
+		"recurrence.on":              " pada %s",
+		"recurrence.until":           " sampai %s",
+		"recurrence.count":           " (%s kali)",
+
+		"today.none":           "📅 Tidak ada rapat hari ini!",
+		"today.all_day":        "🗓️ Seharian:\n",
+		"today.day_of":         " (hari ke-%d dari %d)",
+		"today.header":         "📅 Rapat hari ini:\n\n",
+		"today.at":             " pukul %s",
+		"today.online":         "\n   🎥 Online",
+		"button.join":          "🎥 Gabung",
+		"button.join_numbered": "🎥 Gabung %d. %s",
+
+		"series.which_event":   "Acara yang mana? Sebutkan judulnya.",
+		"series.bad_date":      "Saya tidak mengerti tanggal %q.",
+		"series.not_found":     "🔍 Saya tidak menemukan acara yang cocok dengan \"%s\".",
+		"series.cancelled_all": "🗑️ Semua jadwal \"%s\" dibatalkan.",
+		"series.cancelled":     "🗑️ \"%s\" pada %s dibatalkan.",
+		"series.when":          "Mau dipindah ke kapan? Sebutkan waktu mulai dan selesai yang baru.",
+		"series.moved_all":     "🔁 Semua jadwal \"%s\" sekarang pukul %s.",
+		"series.moved":         "📆 \"%s\" dipindah ke %s %s.",
+		"series.unknown_date":  "tanggal yang tidak diketahui",
+
+		"series.confirm_cancel":     "🗑️ Batalkan \"%s\" pada %s? Balas ya atau tidak.",
+		"series.confirm_cancel_all": "🗑️ Batalkan semua jadwal \"%s\"? Ini tidak bisa dikembalikan. Balas ya atau tidak.",
+		"series.confirm_move":       "📆 Pindahkan \"%s\" dari %s ke %s %s? Balas ya atau tidak.",
+		"series.confirm_move_all":   "🔁 Pindahkan semua jadwal \"%s\" ke pukul %s? Balas ya atau tidak.",
+		"series.kept":               "👍 Baik, \"%s\" tidak diubah.",
+
+		"calendars.help": "Cara pakai:\n" +
+			"/calendars - daftar kalender Anda\n" +
+			"/calendars use 1,3 - pakai kalender untuk agenda dan pengingat\n" +
+			"/calendars default 2 - tempat acara baru disimpan\n" +
+			"/calendars rule kerja 2 - acara yang menyebut \"kerja\" masuk ke kalender 2\n" +
+			"/calendars rules clear - hapus semua aturan",
+		"calendars.not_found_named": "Saya tidak menemukan kalender bernama %q.",
+		"calendars.not_found":       "Saya tidak menemukan kalender itu.",
+		"calendars.using":           "✅ Memakai %d kalender untuk agenda dan pengingat.",
+		"calendars.default_set":     "✅ Acara baru akan masuk ke %s.",
+		"calendars.rule_set":        "✅ Acara yang menyebut \"%s\" akan masuk ke %s.",
+		"calendars.rules_cleared":   "✅ Aturan kalender dihapus.",
+		"calendars.header":          "📚 Kalender Anda:\n\n",
+		"calendars.primary":         " (utama)",
+		"calendars.default":         " ⭐ bawaan",
+		"calendars.rules":           "\n📐 Aturan:\n",
+
+		"contacts.help": "Cara pakai:\n" +
+			"/contacts - daftar kontak dan grup\n" +
+			"/contacts add Rina Putri rina@example.com\n" +
+			"/contacts alias rina@example.com Rin\n" +
+			"/contacts group backend budi@example.com, Rina\n" +
+			"/contacts learn - pelajari nama dari rapat sebelumnya\n" +
+			"Kirim file .csv atau .vcf dengan keterangan /contacts import untuk mengimpor.",
+		"contacts.added":         "✅ %s (%s) ditambahkan.",
+		"contacts.alias_added":   "✅ %s sekarang juga bisa dipanggil %s.",
+		"contacts.unresolved":    "Saya tidak bisa mengenali: %s. Gunakan alamat email atau nama yang jelas.",
+		"contacts.group_set":     "✅ Grup %s berisi %d anggota.",
+		"contacts.learned":       "🧠 Mempelajari %d orang dari rapat %d hari terakhir.",
+		"contacts.import_hint":   "📎 Kirim file .csv atau .vcf sebagai dokumen dengan keterangan /contacts import.",
+		"contacts.too_large":     "❌ File terlalu besar untuk diimpor.",
+		"contacts.import_failed": "❌ Impor gagal: %v",
+		"contacts.imported":      "✅ Mengimpor %d kontak dan %d grup.",
+		"contacts.empty":         "📇 Buku kontak Anda masih kosong.\n\n",
+		"contacts.header":        "📇 %d kontak:\n",
+		"contacts.aka":           " alias %s",
+		"contacts.groups":        "\n👥 Grup:\n",
+
+		"rsvp.none":               "📭 Anda tidak mengadakan rapat dengan tamu dalam %d hari ke depan.",
+		"rsvp.header":             "📬 Konfirmasi kehadiran untuk rapat Anda:\n",
+		"rsvp.status.accepted":    "✅ Hadir",
+		"rsvp.status.tentative":   "🤔 Mungkin",
+		"rsvp.status.declined":    "❌ Tidak hadir",
+		"rsvp.status.needsAction": "⏳ Belum menjawab",
+		"rsvp.declined_notice":    "❌ %s menolak \"%s\" (%s)",
+		"rsvp.comment_notice":     "💬 %s membalas \"%s\" (%s), mungkin mengusulkan waktu lain:\n\n%s",
+
+		"invitation.header":           "📨 Undangan: %s\n\n",
+		"invitation.all_day":          "🗓️ %s (seharian)\n",
+		"invitation.from":             "👤 Dari %s\n",
+		"invitation.online":           "🎥 Rapat online\n",
+		"invitation.answer_in_google": "\n\nSilakan jawab undangan ini di Google Calendar.",
+		"invitation.unknown":          "Jawaban tidak dikenal",
+		"invitation.failed":           "Maaf, saya tidak bisa memperbarui jawaban Anda.",
+		"invitation.owner_only":       "Hanya pemilik kalender yang bisa menjawab ini.",
+		"invitation.accepted":         "✅ Anda menerima",
+		"invitation.tentative":        "🤔 Anda menjawab mungkin",
+		"invitation.declined":         "❌ Anda menolak",
+		"invitation.note_prompt":      "📝 Mau menambahkan catatan untuk penyelenggara? Balas sekarang atau dengan /note <teks> dalam satu jam, atau /skip.",
+		"invitation.note_usage":       "Cara pakai: /note <catatan untuk penyelenggara>",
+		"invitation.no_pending_note":  "Tidak ada undangan yang baru dijawab untuk diberi catatan.",
+		"invitation.no_note":          "👍 Tidak ada catatan.",
+		"invitation.note_added":       "📝 Catatan ditambahkan ke \"%s\".",
+		"button.accept":               "✅ Terima",
+		"button.maybe":                "🤔 Mungkin",
+		"button.decline":              "❌ Tolak",
+
+		"reminder.heading":          "Pengingat Rapat",
+		"reminder.starting_in":      "Dimulai dalam %s",
+		"reminder.attendees":        "👥 Peserta: %s\n\n",
+		"reminder.all_day":          "🗓️ Besok (%s): %s",
+		"reminder.lasts":            "\n\n📆 Berlangsung %d hari",
+		"reminder.test_title":       "Uji coba pengingat",
+		"reminder.test_description": "Pengingat sampai ke chat ini. Tidak ada rapat yang dijadwalkan.",
+
+		"admin.help": "🛠 Perintah admin:\n" +
+			"/users - daftar pengguna dan perannya\n" +
+			"/ban <id|@username> - abaikan pengguna\n" +
+			"/unban <id|@username>\n" +
+			"/stats - penggunaan, panggilan Claude dan error\n" +
+			"/reminders - pengingat yang akan atau sudah dikirim dalam sehari\n" +
+			"Khusus pemilik:\n" +
+			"/promote <id|@username> - jadikan pengguna admin\n" +
+			"/demote <id|@username>\n" +
+			"/broadcast <pesan> - kirim pesan ke semua chat\n" +
+			"/loglevel [level | paket=level] - lihat atau ubah detail log",
+		"admin.owner_only":         "⛔ Hanya pemilik bot yang bisa memakai %s.",
+		"admin.admin_only":         "⛔ Hanya admin bot yang bisa memakai %s.",
+		"admin.no_user":            "❌ Saya tidak mengenal %s. Gunakan ID angka atau @username-nya.",
+		"admin.is_owner":           "❌ %s adalah pemilik; pemilik diatur di konfigurasi.",
+		"admin.outranked":          "❌ Anda tidak bisa mengubah peran %s.",
+		"admin.banned":             "🚫 %s diblokir; saya akan mengabaikan pesannya.",
+		"admin.unbanned":           "✅ %s bisa memakai bot lagi.",
+		"admin.promoted":           "⭐ %s sekarang admin.",
+		"admin.demoted":            "✅ %s bukan admin lagi.",
+		"admin.users_header":       "👥 %d pengguna:\n",
+		"admin.user_line":          "• %s (%d)%s, terakhir terlihat %s\n",
+		"admin.user_unseen":        "• %d%s, belum pernah terlihat\n",
+		"admin.stats":              "📊 Sejak mulai, %s lalu:\n\n👥 Pengguna: %d (%d admin, %d diblokir)\n💬 Chat: %d\n📨 Pesan: %d ditangani, %d gagal, %d ditolak\n🤖 Claude: %d panggilan, %d gagal (%d sibuk), %d fallback offline\n⏰ Pengingat: %d terkirim, %d gagal",
+		"admin.broadcast_usage":    "Cara pakai: /broadcast <pesan>",
+		"admin.broadcast_started":  "📢 Mengirim ke %d chat, nanti saya kabari kalau sudah selesai.",
+		"admin.broadcast_done":     "📢 Terkirim ke %d dari %d chat.",
+		"admin.reminders_off":      "⏰ Pengingat tidak berjalan.",
+		"admin.reminders_none":     "⏰ Tidak ada pengingat yang akan atau sudah dikirim dalam sehari.",
+		"admin.reminders_upcoming": "⏰ Akan dikirim dalam sehari:\n",
+		"admin.reminders_sent":     "✅ Terkirim dalam sehari terakhir:\n",
+		"admin.reminder_line":      "• %s: %s (mulai %s)\n",
+		"admin.loglevel":           "📝 Level log: %s%s\n\nUbah dengan /loglevel <level> atau /loglevel <paket>=<level>, memakai debug, info, warn atau error. Reload konfigurasi akan mengembalikannya.",
+		"admin.loglevel_set":       "✅ Level log sekarang %s.",
+		"admin.loglevel_bad":       "❌ Level log %q tidak dikenal; gunakan debug, info, warn atau error.",
+		"role.banned":              "diblokir",
+		"role.admin":               "admin",
+		"role.owner":               "pemilik",
+
+		"duration.seconds":       "%d detik",
+		"duration.minutes":       "%d menit",
+		"duration.hours":         "%d jam",
+		"duration.hours_minutes": "%d jam %d menit",
+	},
+}
@@ -0,0 +1,132 @@
+// Package i18n holds the bot's English and Indonesian message catalog and
+// formats dates and durations for each language.
+package i18n
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+type Language string
+
+const (
+	English    Language = "en"
+	Indonesian Language = "id"
+
+	// Default is used until we know better from /lang or Telegram
+	Default = English
+)
+
+// Parse accepts the codes and names people are likely to type after /lang.
+func Parse(code string) (Language, bool) {
+	switch strings.ToLower(strings.TrimSpace(code)) {
+	case "en", "eng", "english", "inggris":
+		return English, true
+	case "id", "in", "ind", "indonesia", "indonesian", "bahasa":
+		return Indonesian, true
+	}
+	return "", false
+}
+
+// FromTelegram maps a Telegram language_code ("id", "en-US", ...) to a
+// supported language, falling back to the default.
+func FromTelegram(code string) Language {
+	base := strings.SplitN(strings.ToLower(code), "-", 2)[0]
+	if lang, ok := Parse(base); ok {
+		return lang
+	}
+	return Default
+}
+
+// Name is the language's English name, as used in LLM prompts.
+func (l Language) Name() string {
+	if l == Indonesian {
+		return "Indonesian"
+	}
+	return "English"
+}
+
+// T looks key up in the language's catalog and formats it with args.
+// Missing translations fall back to English, then to the key itself.
+func T(lang Language, key string, args ...interface{}) string {
+	message, ok := catalog[lang][key]
+	if !ok {
+		message, ok = catalog[English][key]
+	}
+	if !ok {
+		message = key
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+var (
+	indonesianWeekdays      = [...]string{"Minggu", "Senin", "Selasa", "Rabu", "Kamis", "Jumat", "Sabtu"}
+	indonesianShortWeekdays = [...]string{"Min", "Sen", "Sel", "Rab", "Kam", "Jum", "Sab"}
+	indonesianMonths        = [...]string{"Januari", "Februari", "Maret", "April", "Mei", "Juni", "Juli", "Agustus", "September", "Oktober", "November", "Desember"}
+	indonesianShortMonths   = [...]string{"Jan", "Feb", "Mar", "Apr", "Mei", "Jun", "Jul", "Agu", "Sep", "Okt", "Nov", "Des"}
+)
+
+// WeekdayName returns the full name of a weekday.
+func WeekdayName(lang Language, day time.Weekday) string {
+	if lang == Indonesian {
+		return indonesianWeekdays[day]
+	}
+	return day.String()
+}
+
+// FormatTime works like t.Format(layout) but with day and month names in
+// the given language.
+func FormatTime(lang Language, t time.Time, layout string) string {
+	if lang != Indonesian {
+		return t.Format(layout)
+	}
+
+	var sb strings.Builder
+	for layout != "" {
+		switch {
+		case strings.HasPrefix(layout, "Monday"):
+			sb.WriteString(indonesianWeekdays[t.Weekday()])
+			layout = layout[len("Monday"):]
+		case strings.HasPrefix(layout, "Mon"):
+			sb.WriteString(indonesianShortWeekdays[t.Weekday()])
+			layout = layout[len("Mon"):]
+		case strings.HasPrefix(layout, "January"):
+			sb.WriteString(indonesianMonths[t.Month()-1])
+			layout = layout[len("January"):]
+		case strings.HasPrefix(layout, "Jan"):
+			sb.WriteString(indonesianShortMonths[t.Month()-1])
+			layout = layout[len("Jan"):]
+		default:
+			// Format everything up to the next name with the standard layout
+			next := len(layout)
+			for _, token := range []string{"Mon", "Jan"} {
+				if i := strings.Index(layout, token); i > 0 && i < next {
+					next = i
+				}
+			}
+			sb.WriteString(t.Format(layout[:next]))
+			layout = layout[next:]
+		}
+	}
+	return sb.String()
+}
+
+// FormatDuration renders a countdown such as "5 minutes" or "1 jam 30 menit".
+func FormatDuration(lang Language, d time.Duration) string {
+	if d < time.Minute {
+		return T(lang, "duration.seconds", int(d.Seconds()))
+	}
+	if d < time.Hour {
+		return T(lang, "duration.minutes", int(d.Minutes()))
+	}
+	hours := int(d.Hours())
+	minutes := int(d.Minutes()) % 60
+	if minutes == 0 {
+		return T(lang, "duration.hours", hours)
+	}
+	return T(lang, "duration.hours_minutes", hours, minutes)
+}
@@ -0,0 +1,64 @@
+// Package lifecycle shuts the assistant down in a fixed order: stop taking
+// updates, finish the ones in hand, stop the background jobs and save state.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"virtual-assistant/internal/logging"
+)
+
+var logger = logging.For("lifecycle")
+
+type step struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// Manager runs shutdown steps in the order they were added.
+type Manager struct {
+	steps []step
+}
+
+func New() *Manager {
+	return &Manager{}
+}
+
+// OnShutdown adds a step. Steps run one after another, so each can rely on
+// the ones before it having finished.
+func (m *Manager) OnShutdown(name string, fn func(ctx context.Context) error) {
+	m.steps = append(m.steps, step{name: name, fn: fn})
+}
+
+// WaitForSignal blocks until SIGINT or SIGTERM.
+func (m *Manager) WaitForSignal() os.Signal {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(c)
+	return <-c
+}
+
+// Shutdown runs every step under one deadline. A failing step is logged and
+// the rest still run, so state is saved even if draining timed out.
+func (m *Manager) Shutdown(timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var errs []error
+	for _, s := range m.steps {
+		start := time.Now()
+		if err := s.fn(ctx); err != nil {
+			logger.Error("shutdown step failed", "step", s.name, "elapsed", time.Since(start).Round(time.Millisecond).String(), "error", err)
+			errs = append(errs, fmt.Errorf("%s: %v", s.name, err))
+			continue
+		}
+		logger.Info("shutdown step done", "step", s.name, "elapsed", time.Since(start).Round(time.Millisecond).String())
+	}
+	return errors.Join(errs...)
+}
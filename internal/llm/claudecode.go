@@ -4,13 +4,36 @@ import (
 	"bufio"
 	"context"
 	"fmt"
+	"io"
 	"os/exec"
 	"strings"
+	"sync/atomic"
+	"text/template"
 	"time"
+
+	"virtual-assistant/internal/logging"
+	"virtual-assistant/internal/timezone"
 )
 
+var logger = logging.For("llm")
+
+// DefaultTimeout bounds a single claude run, not counting time spent in the
+// queue.
+const DefaultTimeout = 30 * time.Second
+
 type ClaudeCodeService struct {
 	claudeCodePath string
+	queue          *queue
+	timeout        atomic.Int64 // time.Duration
+	instructions   atomic.Pointer[template.Template]
+}
+
+// InstructionsData is what the instructions template can refer to, e.g.
+// {{if eq .Language "Indonesian"}}Use "Anda".{{end}}
+type InstructionsData struct {
+	Prompt   string    // Which prompt they're added to: calendar_command or general_chat
+	Language string    // The user's language by name, e.g. "Indonesian"
+	Now      time.Time // The current time in the configured timezone
 }
 
 func NewClaudeCodeService(claudeCodePath string) (*ClaudeCodeService, error) {
@@ -22,10 +45,110 @@ func NewClaudeCodeService(claudeCodePath string) (*ClaudeCodeService, error) {
 		return nil, fmt.Errorf("claude code not found in PATH. Please ensure Claude Code is installed and accessible. Error: %v", err)
 	}
 
-	return &ClaudeCodeService{claudeCodePath: claudeCodePath}, nil
+	ccs := &ClaudeCodeService{
+		claudeCodePath: claudeCodePath,
+		queue:          newQueue(DefaultConcurrency, DefaultQueueSize),
+	}
+	ccs.SetTimeout(DefaultTimeout)
+	return ccs, nil
+}
+
+// SetLimits sets how many claude processes may run at once and how many
+// requests may wait for one before ErrBusy is returned.
+func (ccs *ClaudeCodeService) SetLimits(concurrency, queueSize int) {
+	ccs.queue = newQueue(concurrency, queueSize)
+}
+
+// SetTimeout sets how long a single claude run may take. Runs already going
+// keep the timeout they started with.
+func (ccs *ClaudeCodeService) SetTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ccs.timeout.Store(int64(timeout))
+}
+
+// ParseInstructions parses instructions as a text/template over
+// InstructionsData and tries it on sample data, so a mistake shows up in
+// `config check` rather than in the middle of a request.
+func ParseInstructions(instructions string) (*template.Template, error) {
+	tmpl, err := template.New("instructions").Option("missingkey=error").Parse(instructions)
+	if err != nil {
+		return nil, err
+	}
+	sample := InstructionsData{Prompt: "general_chat", Language: "English", Now: time.Now().In(timezone.Location())}
+	if err := tmpl.Execute(io.Discard, sample); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+// SetInstructions sets extra instructions added to every prompt, such as the
+// assistant's tone or house rules, as a template (see ParseInstructions).
+// They can be changed while running; invalid ones leave the current ones in
+// place.
+func (ccs *ClaudeCodeService) SetInstructions(instructions string) error {
+	tmpl, err := ParseInstructions(instructions)
+	if err != nil {
+		return fmt.Errorf("invalid instructions: %v", err)
+	}
+	ccs.instructions.Store(tmpl)
+	return nil
+}
+
+// withInstructions appends the configured instructions to prompt. They come
+// last but may not change the answer format, which the bot parses.
+func (ccs *ClaudeCodeService) withInstructions(prompt string, data InstructionsData) string {
+	tmpl := ccs.instructions.Load()
+	if tmpl == nil {
+		return prompt
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		logger.Warn("leaving out prompt instructions", "prompt", data.Prompt, "error", err)
+		return prompt
+	}
+	instructions := strings.TrimSpace(sb.String())
+	if instructions == "" {
+		return prompt
+	}
+	return prompt + "\n\nAdditional instructions from the assistant's owner (keep the response format above):\n" + instructions
+}
+
+// Health checks that the claude binary is still there and starts. It doesn't
+// take a slot in the queue, so it works while every slot is busy.
+func (ccs *ClaudeCodeService) Health(ctx context.Context) (string, error) {
+	path, err := exec.LookPath(ccs.claudeCodePath)
+	if err != nil {
+		return "", fmt.Errorf("claude code not found: %v", err)
+	}
+
+	output, err := exec.CommandContext(ctx, path, "--version").Output()
+	if err != nil {
+		return "", fmt.Errorf("claude code doesn't start: %v", err)
+	}
+	return strings.TrimSpace(string(output)), nil
 }
 
 func (ccs *ClaudeCodeService) GenerateResponse(ctx context.Context, prompt string) (string, error) {
+	return ccs.generate(ctx, "generate", prompt)
+}
+
+// generate is GenerateResponse with the outcome recorded under operation.
+func (ccs *ClaudeCodeService) generate(ctx context.Context, operation, prompt string) (response string, err error) {
+	release, err := ccs.queue.acquire(ctx)
+	if err != nil {
+		requestsTotal.WithLabelValues(operation, result(ctx, err)).Inc()
+		return "", err
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(ccs.timeout.Load()))
+	defer cancel()
+
+	start := time.Now()
+	defer func() { observe(operation, start, ctx, err) }()
+
 	// Use --print flag for non-interactive output and pass prompt directly
 	cmd := exec.CommandContext(ctx, ccs.claudeCodePath, "--print", prompt)
 	
@@ -37,7 +160,7 @@ func (ccs *ClaudeCodeService) GenerateResponse(ctx context.Context, prompt strin
 		return "", fmt.Errorf("failed to execute claude code: %v", err)
 	}
 
-	response := strings.TrimSpace(string(output))
+	response = strings.TrimSpace(string(output))
 	if response == "" {
 		return "I apologize, but I couldn't generate a response at the moment.", nil
 	}
@@ -45,7 +168,17 @@ func (ccs *ClaudeCodeService) GenerateResponse(ctx context.Context, prompt strin
 	return response, nil
 }
 
-func (ccs *ClaudeCodeService) GenerateResponseInteractive(ctx context.Context, prompt string) (string, error) {
+func (ccs *ClaudeCodeService) GenerateResponseInteractive(ctx context.Context, prompt string) (_ string, err error) {
+	release, err := ccs.queue.acquire(ctx)
+	if err != nil {
+		requestsTotal.WithLabelValues("interactive", result(ctx, err)).Inc()
+		return "", err
+	}
+	defer release()
+
+	start := time.Now()
+	defer func() { observe("interactive", start, ctx, err) }()
+
 	cmd := exec.CommandContext(ctx, ccs.claudeCodePath)
 	
 	stdin, err := cmd.StdinPipe()
@@ -71,7 +204,7 @@ func (ccs *ClaudeCodeService) GenerateResponseInteractive(ctx context.Context, p
 	var response strings.Builder
 	scanner := bufio.NewScanner(stdout)
 	
-	timeout := time.After(30 * time.Second)
+	timeout := time.After(time.Duration(ccs.timeout.Load()))
 	done := make(chan bool)
 	
 	go func() {
@@ -108,35 +241,58 @@ func (ccs *ClaudeCodeService) GenerateResponseInteractive(ctx context.Context, p
 	return result, nil
 }
 
-func (ccs *ClaudeCodeService) ProcessCalendarCommand(ctx context.Context, userMessage string) (string, error) {
-	// Get current time in Indonesia timezone
-	indonesiaLocation, _ := time.LoadLocation("Asia/Jakarta")
-	currentTime := time.Now().In(indonesiaLocation)
+// ProcessCalendarCommand asks Claude to classify a message and extract the
+// event details. language is the user's language by name, e.g. "Indonesian".
+func (ccs *ClaudeCodeService) ProcessCalendarCommand(ctx context.Context, userMessage, language string) (string, error) {
+	// Get current time in the local timezone
+	loc := timezone.Location()
+	currentTime := time.Now().In(loc)
 	currentDateStr := currentTime.Format("2006-01-02")
+	offset := currentTime.Format("-07:00")
 	
 	prompt := fmt.Sprintf(`You are a helpful virtual assistant for managing Google Calendar events and meetings. 
 The user said: "%s"
 
 IMPORTANT CONTEXT:
-- Current date and time in Indonesia (Asia/Jakarta timezone): %s
+- Current date and time in the user's timezone (%s): %s
 - Today's date is: %s
-- Use Indonesia timezone (+07:00) for all times
+- Use the %s timezone (%s) for all times
 - When user says "today", use today's date: %s
 - When user says "tomorrow", use: %s
+- The user's language is %s: write any RESPONSE text in %s, and keep TITLE and DESCRIPTION in the user's own words
 
 Please analyze this message and determine what the user wants to do:
-1. Create a calendar event - extract title, description, date/time, attendees
+1. Create a calendar event - extract title, description, date/time, attendees (emails, people or groups), recurrence
 2. Check today's meetings - list today's schedule
-3. General query - provide helpful response
+3. Cancel or reschedule an existing event - one occurrence or the whole recurring series
+4. General query - provide helpful response
 
 Respond in a structured way that clearly indicates the action needed and any extracted information.
 If creating an event, provide the details in this format:
 ACTION: CREATE_EVENT
 TITLE: [event title]
 DESCRIPTION: [event description]  
-START_TIME: [ISO format date-time like %sT14:00:00+07:00 for Indonesia timezone]
-END_TIME: [ISO format date-time like %sT15:00:00+07:00 for Indonesia timezone]
-ATTENDEES: [comma-separated email addresses if mentioned, or empty if none]
+ALL_DAY: [YES for all-day or multi-day events such as holidays, time off or conferences, otherwise NO]
+START_TIME: [ISO format date-time like %sT14:00:00%s; for all-day events the first date like %s]
+END_TIME: [ISO format date-time like %sT15:00:00%s; for all-day events the last date, inclusive]
+ATTENDEES: [comma-separated email addresses, names or group names exactly as the user said them (e.g. Rina, backend team), or empty if none]
+ONLINE: [YES if the user wants an online/video meeting (Meet, video call, virtual, remote), NO if it's explicitly in person, or empty if not mentioned]
+CALENDAR: [name of the calendar if the user explicitly names one, e.g. Work or Personal, or empty otherwise]
+RECURRENCE: [RRULE such as RRULE:FREQ=WEEKLY;BYDAY=TU;UNTIL=20261231T235959Z if the event repeats, or empty if it doesn't]
+
+If cancelling an event:
+ACTION: CANCEL_EVENT
+EVENT: [title or keywords of the existing event]
+DATE: [YYYY-MM-DD of the occurrence, or empty if not mentioned]
+SCOPE: [OCCURRENCE for only that occurrence, SERIES for every occurrence of a recurring event]
+
+If moving an event to another time:
+ACTION: RESCHEDULE_EVENT
+EVENT: [title or keywords of the existing event]
+DATE: [YYYY-MM-DD of the occurrence being moved, or empty if not mentioned]
+SCOPE: [OCCURRENCE or SERIES, as above]
+START_TIME: [new ISO format date-time]
+END_TIME: [new ISO format date-time]
 
 If checking meetings:
 ACTION: CHECK_TODAY
@@ -147,28 +303,32 @@ RESPONSE: [your helpful response]
 
 Be concise and format the response exactly as shown above.`, 
 		userMessage, 
+		timezone.Name(),
 		currentTime.Format("2006-01-02 15:04:05 MST"), 
 		currentDateStr,
+		timezone.Name(),
+		offset,
 		currentDateStr,
 		currentTime.AddDate(0, 0, 1).Format("2006-01-02"),
+		language,
+		language,
 		currentDateStr,
-		currentDateStr)
-
-	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
-	defer cancel()
+		offset,
+		currentDateStr,
+		currentDateStr,
+		offset)
 
-	return ccs.GenerateResponse(ctx, prompt)
+	data := InstructionsData{Prompt: "calendar_command", Language: language, Now: currentTime}
+	return ccs.generate(ctx, "calendar_command", ccs.withInstructions(prompt, data))
 }
 
-func (ccs *ClaudeCodeService) GeneralChat(ctx context.Context, userMessage string) (string, error) {
+func (ccs *ClaudeCodeService) GeneralChat(ctx context.Context, userMessage, language string) (string, error) {
 	prompt := fmt.Sprintf(`You are a helpful AI assistant. The user is chatting with you directly.
 
 User message: "%s"
 
-Please provide a helpful, conversational response. Keep it friendly and concise.`, userMessage)
-
-	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
-	defer cancel()
+Please provide a helpful, conversational response in %s. Keep it friendly and concise.`, userMessage, language)
 
-	return ccs.GenerateResponse(ctx, prompt)
+	data := InstructionsData{Prompt: "general_chat", Language: language, Now: time.Now().In(timezone.Location())}
+	return ccs.generate(ctx, "general_chat", ccs.withInstructions(prompt, data))
 }
\ No newline at end of file
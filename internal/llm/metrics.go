@@ -0,0 +1,60 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	requestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "assistant_llm_requests_total",
+		Help: "Claude requests by operation and result (ok, error, timeout, busy, cancelled).",
+	}, []string{"operation", "result"})
+
+	requestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "assistant_llm_duration_seconds",
+		Help:    "How long claude ran, not counting time in the queue.",
+		Buckets: []float64{1, 2, 5, 10, 15, 20, 30, 60},
+	}, []string{"operation"})
+
+	queueWait = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "assistant_llm_queue_wait_seconds",
+		Help:    "How long requests waited for a free claude slot.",
+		Buckets: []float64{0.1, 0.5, 1, 5, 10, 30, 60},
+	})
+
+	queueDepth = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "assistant_llm_queue_depth",
+		Help: "Requests waiting for a claude slot.",
+	})
+
+	running = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "assistant_llm_running",
+		Help: "claude processes running.",
+	})
+)
+
+// observe records the outcome of a claude run. ctx is the run's own context,
+// which tells a timeout apart from other failures.
+func observe(operation string, start time.Time, ctx context.Context, err error) {
+	requestDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	requestsTotal.WithLabelValues(operation, result(ctx, err)).Inc()
+}
+
+func result(ctx context.Context, err error) string {
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.Is(err, ErrBusy):
+		return "busy"
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return "timeout"
+	case ctx.Err() != nil:
+		return "cancelled"
+	}
+	return "error"
+}
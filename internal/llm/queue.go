@@ -0,0 +1,186 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultConcurrency = 2
+	DefaultQueueSize   = 20
+)
+
+// ErrBusy is returned when too many requests are already waiting for Claude.
+var ErrBusy = errors.New("claude is busy: request queue is full")
+
+type requesterKey struct{}
+
+type requester struct {
+	id       string
+	onQueued func(position int)
+}
+
+// WithRequester tags the requests made with ctx as coming from id, so the
+// queue can take turns between users. onQueued, if set, is called with the
+// request's 1-based place in line when it has to wait.
+func WithRequester(ctx context.Context, id string, onQueued func(position int)) context.Context {
+	return context.WithValue(ctx, requesterKey{}, requester{id: id, onQueued: onQueued})
+}
+
+// queue limits how many claude processes run at once. Waiting requests are
+// served round-robin by requester, so one user sending a burst of messages
+// can't starve the others.
+type queue struct {
+	mu       sync.Mutex
+	limit    int
+	capacity int
+	running  int
+	waiting  map[string][]chan struct{} // Per requester, oldest first
+	order    []string                   // Requesters with waiting requests, next to be served first
+	size     int
+}
+
+func newQueue(limit, capacity int) *queue {
+	if limit < 1 {
+		limit = DefaultConcurrency
+	}
+	if capacity < 0 {
+		capacity = DefaultQueueSize
+	}
+	return &queue{
+		limit:    limit,
+		capacity: capacity,
+		waiting:  make(map[string][]chan struct{}),
+	}
+}
+
+// acquire waits for a free slot. The returned func must be called when the
+// process has finished.
+func (q *queue) acquire(ctx context.Context) (func(), error) {
+	req, _ := ctx.Value(requesterKey{}).(requester)
+
+	q.mu.Lock()
+	if q.running < q.limit && q.size == 0 {
+		q.running++
+		q.report()
+		q.mu.Unlock()
+		queueWait.Observe(0)
+		return q.release, nil
+	}
+	if q.size >= q.capacity {
+		q.mu.Unlock()
+		return nil, ErrBusy
+	}
+
+	ready := make(chan struct{})
+	if len(q.waiting[req.id]) == 0 {
+		q.order = append(q.order, req.id)
+	}
+	q.waiting[req.id] = append(q.waiting[req.id], ready)
+	q.size++
+	q.report()
+	position := q.position(req.id, len(q.waiting[req.id])-1)
+	q.mu.Unlock()
+	start := time.Now()
+
+	if req.onQueued != nil {
+		req.onQueued(position)
+	}
+
+	select {
+	case <-ready:
+		queueWait.Observe(time.Since(start).Seconds())
+		return q.release, nil
+	case <-ctx.Done():
+		q.mu.Lock()
+		removed := q.remove(req.id, ready)
+		q.mu.Unlock()
+		if !removed {
+			// The slot was handed over just as we gave up
+			q.release()
+		}
+		return nil, ctx.Err()
+	}
+}
+
+func (q *queue) release() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.running--
+	for q.running < q.limit && len(q.order) > 0 {
+		id := q.order[0]
+		q.order = q.order[1:]
+
+		waiting := q.waiting[id]
+		if len(waiting) > 1 {
+			q.waiting[id] = waiting[1:]
+			q.order = append(q.order, id)
+		} else {
+			delete(q.waiting, id)
+		}
+
+		q.size--
+		q.running++
+		close(waiting[0])
+	}
+	q.report()
+}
+
+// report publishes the queue's state; q.mu must be held.
+func (q *queue) report() {
+	queueDepth.Set(float64(q.size))
+	running.Set(float64(q.running))
+}
+
+// position works out where the n-th waiting request of id is in line,
+// given that requesters take turns.
+func (q *queue) position(id string, n int) int {
+	ahead := 0
+	passed := false
+	for _, other := range q.order {
+		waiting := len(q.waiting[other])
+		switch {
+		case other == id:
+			passed = true
+			ahead += n
+		case waiting <= n:
+			ahead += waiting
+		case passed:
+			ahead += n
+		default:
+			ahead += n + 1
+		}
+	}
+	return ahead + 1
+}
+
+// remove drops a request that gave up waiting. It reports false if the
+// request was already given a slot.
+func (q *queue) remove(id string, ready chan struct{}) bool {
+	waiting := q.waiting[id]
+	for i, r := range waiting {
+		if r != ready {
+			continue
+		}
+		waiting = append(waiting[:i:i], waiting[i+1:]...)
+		q.size--
+		q.report()
+		if len(waiting) > 0 {
+			q.waiting[id] = waiting
+			return true
+		}
+
+		delete(q.waiting, id)
+		for j, other := range q.order {
+			if other == id {
+				q.order = append(q.order[:j:j], q.order[j+1:]...)
+				break
+			}
+		}
+		return true
+	}
+	return false
+}
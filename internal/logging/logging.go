@@ -0,0 +1,205 @@
+// Package logging sets up structured logging with log/slog. Each package
+// gets its own logger from For, whose level can be set separately, and
+// personal data is redacted before anything is written.
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// Options configure the output of every logger.
+type Options struct {
+	Level         string // Default level: debug, info, warn or error
+	PackageLevels string // Per-package overrides, e.g. "bot=debug,calendar=warn"
+	Format        string // "json" or "text"
+	Redact        bool   // Hide message text, emails and names
+	Output        io.Writer
+}
+
+type root struct {
+	handler slog.Handler
+	level   slog.Level
+	levels  map[string]slog.Level
+}
+
+var current atomic.Pointer[root]
+
+func init() {
+	current.Store(&root{
+		handler: slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug, ReplaceAttr: redactor(true)}),
+		level:   slog.LevelInfo,
+	})
+}
+
+// Setup applies opts to every logger, including ones created before it was
+// called, and routes the standard log package through them too.
+func Setup(opts Options) error {
+	level, err := parseLevel(opts.Level)
+	if err != nil {
+		return err
+	}
+
+	levels := make(map[string]slog.Level)
+	for _, entry := range strings.Split(opts.PackageLevels, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		pkg, value, found := strings.Cut(entry, "=")
+		if !found {
+			return fmt.Errorf("invalid package log level %q, expected package=level", entry)
+		}
+		pkgLevel, err := parseLevel(value)
+		if err != nil {
+			return err
+		}
+		levels[strings.TrimSpace(pkg)] = pkgLevel
+	}
+
+	output := opts.Output
+	if output == nil {
+		output = os.Stderr
+	}
+	// Filtering happens per package, so the handler itself lets everything through
+	handlerOpts := &slog.HandlerOptions{Level: slog.LevelDebug, ReplaceAttr: redactor(opts.Redact)}
+
+	var handler slog.Handler
+	switch strings.ToLower(opts.Format) {
+	case "", "json":
+		handler = slog.NewJSONHandler(output, handlerOpts)
+	case "text":
+		handler = slog.NewTextHandler(output, handlerOpts)
+	default:
+		return fmt.Errorf("unknown log format %q, expected json or text", opts.Format)
+	}
+
+	current.Store(&root{handler: handler, level: level, levels: levels})
+	slog.SetDefault(For("main"))
+	return nil
+}
+
+// SetLevel changes the level of one package, or the default level when pkg
+// is empty, until the next Setup.
+func SetLevel(pkg, value string) error {
+	level, err := parseLevel(value)
+	if err != nil {
+		return err
+	}
+
+	// Retry if Setup or another SetLevel got in between
+	for {
+		old := current.Load()
+		next := &root{handler: old.handler, level: old.level, levels: make(map[string]slog.Level, len(old.levels)+1)}
+		for name, l := range old.levels {
+			next.levels[name] = l
+		}
+		if pkg == "" {
+			next.level = level
+		} else {
+			next.levels[pkg] = level
+		}
+		if current.CompareAndSwap(old, next) {
+			return nil
+		}
+	}
+}
+
+// Levels returns the default level and the per-package overrides.
+func Levels() (slog.Level, map[string]slog.Level) {
+	r := current.Load()
+	levels := make(map[string]slog.Level, len(r.levels))
+	for pkg, level := range r.levels {
+		levels[pkg] = level
+	}
+	return r.level, levels
+}
+
+func parseLevel(value string) (slog.Level, error) {
+	var level slog.Level
+	if strings.TrimSpace(value) == "" {
+		return slog.LevelInfo, nil
+	}
+	if err := level.UnmarshalText([]byte(strings.TrimSpace(value))); err != nil {
+		return level, fmt.Errorf("invalid log level %q", value)
+	}
+	return level, nil
+}
+
+func (r *root) levelFor(pkg string) slog.Level {
+	if level, ok := r.levels[pkg]; ok {
+		return level
+	}
+	return r.level
+}
+
+// For returns the logger for a package. It's safe to keep in a package
+// variable: it follows whatever Setup configures later.
+func For(pkg string) *slog.Logger {
+	return slog.New(&packageHandler{pkg: pkg})
+}
+
+// packageHandler hands records to the current root handler, so loggers made
+// at init time pick up the configuration.
+type packageHandler struct {
+	pkg string
+	ops []func(slog.Handler) slog.Handler // WithAttrs and WithGroup calls, in order
+}
+
+func (h *packageHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= current.Load().levelFor(h.pkg)
+}
+
+func (h *packageHandler) Handle(ctx context.Context, record slog.Record) error {
+	handler := current.Load().handler.WithAttrs([]slog.Attr{slog.String("pkg", h.pkg)})
+	if id := RequestID(ctx); id != "" {
+		handler = handler.WithAttrs([]slog.Attr{slog.String("request_id", id)})
+	}
+	for _, op := range h.ops {
+		handler = op(handler)
+	}
+	return handler.Handle(ctx, record)
+}
+
+func (h *packageHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.with(func(handler slog.Handler) slog.Handler { return handler.WithAttrs(attrs) })
+}
+
+func (h *packageHandler) WithGroup(name string) slog.Handler {
+	return h.with(func(handler slog.Handler) slog.Handler { return handler.WithGroup(name) })
+}
+
+func (h *packageHandler) with(op func(slog.Handler) slog.Handler) slog.Handler {
+	ops := append(append([]func(slog.Handler) slog.Handler(nil), h.ops...), op)
+	return &packageHandler{pkg: h.pkg, ops: ops}
+}
+
+type requestIDKey struct{}
+
+// WithRequestID tags ctx so every line logged with it carries id.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the ID set by WithRequestID, or "".
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// NewRequestID returns a random ID for one update.
+func NewRequestID() string {
+	buf := make([]byte, 8)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,59 @@
+package logging
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+)
+
+var (
+	// Bot tokens look like 123456789:AA...; they also appear in API URLs
+	tokenPattern = regexp.MustCompile(`\d{5,}:[A-Za-z0-9_-]{30,}`)
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+)
+
+// contentKeys hold what users wrote or what's in their calendar. With
+// redaction on only their length is logged.
+var contentKeys = map[string]bool{
+	"text":        true,
+	"prompt":      true,
+	"response":    true,
+	"comment":     true,
+	"description": true,
+	"title":       true,
+	"name":        true,
+	"preview":     true,
+	"payload":     true,
+}
+
+// redactor returns the ReplaceAttr function for the handlers. Tokens are
+// always hidden; message content, emails and names only when redact is set.
+func redactor(redact bool) func(groups []string, a slog.Attr) slog.Attr {
+	return func(groups []string, a slog.Attr) slog.Attr {
+		value := a.Value.Resolve()
+
+		var text string
+		switch value.Kind() {
+		case slog.KindString:
+			text = value.String()
+		case slog.KindAny:
+			err, ok := value.Any().(error)
+			if !ok {
+				return a
+			}
+			text = err.Error()
+		default:
+			return a
+		}
+
+		if redact && contentKeys[a.Key] {
+			return slog.String(a.Key, fmt.Sprintf("[redacted, %d chars]", len([]rune(text))))
+		}
+
+		text = tokenPattern.ReplaceAllString(text, "[token]")
+		if redact {
+			text = emailPattern.ReplaceAllString(text, "[email]")
+		}
+		return slog.String(a.Key, text)
+	}
+}
@@ -0,0 +1,81 @@
+package markup
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	fencePattern      = regexp.MustCompile("(?s)```[A-Za-z0-9_+-]*\\n?(.*?)```")
+	inlineCodePattern = regexp.MustCompile("`([^`\\n]+)`")
+	linkPattern       = regexp.MustCompile(`\[([^\]\n]+)\]\((https?://[^)\s]+)\)`)
+	headingPattern    = regexp.MustCompile(`(?m)^#{1,6}\s+(.+)$`)
+	bulletPattern     = regexp.MustCompile(`(?m)^(\s*)[-*+]\s+`)
+	boldPattern       = regexp.MustCompile(`\*\*([^\n]+?)\*\*|__([^\n]+?)__`)
+	strikePattern     = regexp.MustCompile(`~~([^\n]+?)~~`)
+	italicPattern     = regexp.MustCompile(`(^|[^\w*])\*([^*\s][^*\n]*?)\*($|[^\w*])|(^|[^\w_])_([^_\s][^_\n]*?)_($|[^\w_])`)
+	placeholderRegex  = regexp.MustCompile("\x00([0-9]+)\x00")
+	tagPattern        = regexp.MustCompile(`</?([a-z]+)[^>]*>`)
+)
+
+// FromMarkdown converts the Markdown an LLM tends to produce (bold, italic,
+// code, links, headings, bullets) into Telegram HTML. If the result isn't
+// well formed, the text is sent escaped as-is instead.
+func FromMarkdown(markdown string) HTML {
+	// Code is taken out first so nothing inside it is treated as formatting
+	var blocks []HTML
+	stash := func(html HTML) string {
+		blocks = append(blocks, html)
+		return fmt.Sprintf("\x00%d\x00", len(blocks)-1)
+	}
+	text := fencePattern.ReplaceAllStringFunc(markdown, func(match string) string {
+		return stash(Pre(strings.TrimRight(fencePattern.FindStringSubmatch(match)[1], "\n")))
+	})
+	text = inlineCodePattern.ReplaceAllStringFunc(text, func(match string) string {
+		return stash(Code(inlineCodePattern.FindStringSubmatch(match)[1]))
+	})
+	text = linkPattern.ReplaceAllStringFunc(text, func(match string) string {
+		parts := linkPattern.FindStringSubmatch(match)
+		return stash(Link(parts[1], parts[2]))
+	})
+
+	html := string(Escape(text))
+	html = headingPattern.ReplaceAllString(html, "<b>$1</b>")
+	html = bulletPattern.ReplaceAllString(html, "$1• ")
+	html = boldPattern.ReplaceAllString(html, "<b>$1$2</b>")
+	html = strikePattern.ReplaceAllString(html, "<s>$1</s>")
+	// Neighbouring matches share a boundary character, so go round twice
+	for i := 0; i < 2; i++ {
+		html = italicPattern.ReplaceAllString(html, "$1$4<i>$2$5</i>$3$6")
+	}
+
+	html = placeholderRegex.ReplaceAllStringFunc(html, func(match string) string {
+		n, _ := strconv.Atoi(strings.Trim(match, "\x00"))
+		return string(blocks[n])
+	})
+
+	if !wellFormed(html) {
+		return Escape(markdown)
+	}
+	return HTML(html)
+}
+
+// wellFormed reports whether every tag is closed in the right order, which
+// Telegram insists on.
+func wellFormed(html string) bool {
+	var open []string
+	for _, match := range tagPattern.FindAllStringSubmatch(html, -1) {
+		name := match[1]
+		if !strings.HasPrefix(match[0], "</") {
+			open = append(open, name)
+			continue
+		}
+		if len(open) == 0 || open[len(open)-1] != name {
+			return false
+		}
+		open = open[:len(open)-1]
+	}
+	return len(open) == 0
+}
@@ -0,0 +1,77 @@
+// Package markup renders messages as Telegram HTML. Everything that comes
+// from users, calendars or the LLM is escaped; formatting is only added
+// through the helpers here, so a stray "<" in an event title can't make
+// Telegram reject the whole message.
+package markup
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// HTML is text that is already valid Telegram HTML.
+type HTML string
+
+// MaxMessageLength is Telegram's limit for a single message.
+const MaxMessageLength = 4096
+
+var escaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+
+// PlainText strips the formatting from text, for clients that don't render
+// HTML.
+func PlainText(text HTML) string {
+	return html.UnescapeString(stripTags(string(text)))
+}
+
+// Escape makes plain text safe to send with the HTML parse mode.
+func Escape(text string) HTML {
+	return HTML(escaper.Replace(text))
+}
+
+func Bold(text string) HTML {
+	return "<b>" + Escape(text) + "</b>"
+}
+
+func Italic(text string) HTML {
+	return "<i>" + Escape(text) + "</i>"
+}
+
+func Code(text string) HTML {
+	return "<code>" + Escape(text) + "</code>"
+}
+
+func Pre(text string) HTML {
+	return "<pre>" + Escape(text) + "</pre>"
+}
+
+// Link renders a hyperlink. Anything other than an http(s) URL is shown as
+// plain text rather than trusted as a link.
+func Link(text, url string) HTML {
+	if !strings.HasPrefix(url, "https://") && !strings.HasPrefix(url, "http://") {
+		return Escape(text)
+	}
+	return HTML(`<a href="`) + Escape(url) + `">` + Escape(text) + "</a>"
+}
+
+// Sprintf works like fmt.Sprintf, but escapes the format and every argument
+// that isn't already HTML. Use it to fill catalog strings with formatted
+// values, e.g. Sprintf(format, Bold(title)).
+func Sprintf(format string, args ...interface{}) HTML {
+	safe := make([]interface{}, len(args))
+	for i, arg := range args {
+		switch value := arg.(type) {
+		case HTML:
+			safe[i] = string(value)
+		case string:
+			safe[i] = string(Escape(value))
+		case error:
+			safe[i] = string(Escape(value.Error()))
+		case fmt.Stringer:
+			safe[i] = string(Escape(value.String()))
+		default:
+			safe[i] = arg
+		}
+	}
+	return HTML(fmt.Sprintf(string(Escape(format)), safe...))
+}
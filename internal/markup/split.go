@@ -0,0 +1,137 @@
+package markup
+
+import (
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Split breaks a message into chunks of at most limit characters (counted
+// in UTF-16 units, like Telegram does), preferring line breaks, then spaces.
+// Tags open at a cut are closed at the end of one chunk and reopened at the
+// start of the next, so every chunk is valid HTML on its own.
+func Split(text HTML, limit int) []HTML {
+	if textLength(string(text)) <= limit {
+		return []HTML{text}
+	}
+
+	tokens := tokenize(string(text))
+	var chunks []HTML
+	var open []string // Opening tags carried over from the previous chunk
+
+	for start := 0; start < len(tokens); {
+		size := textLength(strings.Join(open, ""))
+		stack := append([]string(nil), open...)
+
+		end := start
+		lineBreak, space := -1, -1
+		var stackAtLine, stackAtSpace []string
+		for end < len(tokens) {
+			next := applyTag(stack, tokens[end])
+			if size+textLength(tokens[end])+closingLength(next) > limit {
+				break
+			}
+			size += textLength(tokens[end])
+			stack = next
+			end++
+
+			switch tokens[end-1] {
+			case "\n":
+				lineBreak, stackAtLine = end, append([]string(nil), stack...)
+			case " ":
+				space, stackAtSpace = end, append([]string(nil), stack...)
+			}
+		}
+
+		if end < len(tokens) {
+			if lineBreak > start {
+				end, stack = lineBreak, stackAtLine
+			} else if space > start {
+				end, stack = space, stackAtSpace
+			}
+		}
+		if end == start {
+			// A single token longer than the limit; send it anyway
+			stack = applyTag(stack, tokens[end])
+			end++
+		}
+
+		chunk := strings.Join(open, "") + strings.Join(tokens[start:end], "") + closingTags(stack)
+		if strings.TrimSpace(stripTags(chunk)) != "" {
+			chunks = append(chunks, HTML(chunk))
+		}
+
+		open = stack
+		start = end
+	}
+
+	return chunks
+}
+
+// tokenize splits HTML into tags, entities and single characters, so cuts
+// never land inside a tag or an entity.
+func tokenize(html string) []string {
+	var tokens []string
+	for len(html) > 0 {
+		switch html[0] {
+		case '<':
+			if end := strings.IndexByte(html, '>'); end > 0 {
+				tokens = append(tokens, html[:end+1])
+				html = html[end+1:]
+				continue
+			}
+		case '&':
+			if end := strings.IndexByte(html, ';'); end > 0 && end < 10 {
+				tokens = append(tokens, html[:end+1])
+				html = html[end+1:]
+				continue
+			}
+		}
+		_, size := utf8.DecodeRuneInString(html)
+		tokens = append(tokens, html[:size])
+		html = html[size:]
+	}
+	return tokens
+}
+
+// applyTag returns the open-tag stack after token.
+func applyTag(stack []string, token string) []string {
+	if !strings.HasPrefix(token, "<") || len(token) < 3 {
+		return stack
+	}
+	if strings.HasPrefix(token, "</") {
+		if len(stack) > 0 {
+			return stack[:len(stack)-1]
+		}
+		return stack
+	}
+	return append(append([]string(nil), stack...), token)
+}
+
+func closingTags(stack []string) string {
+	var sb strings.Builder
+	for i := len(stack) - 1; i >= 0; i-- {
+		sb.WriteString("</" + tagName(stack[i]) + ">")
+	}
+	return sb.String()
+}
+
+func closingLength(stack []string) int {
+	return textLength(closingTags(stack))
+}
+
+func tagName(tag string) string {
+	name := strings.TrimPrefix(strings.TrimSuffix(tag, ">"), "<")
+	if i := strings.IndexAny(name, " \t\n"); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+func stripTags(html string) string {
+	return tagPattern.ReplaceAllString(html, "")
+}
+
+func textLength(s string) int {
+	return len(utf16.Encode([]rune(s)))
+}
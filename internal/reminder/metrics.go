@@ -0,0 +1,23 @@
+package reminder
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	remindersSent = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "assistant_reminders_sent_total",
+		Help: "Reminders delivered, by kind (meeting, all_day).",
+	}, []string{"kind"})
+
+	remindersFailed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "assistant_reminders_failed_total",
+		Help: "Reminders that couldn't be delivered, by kind (meeting, all_day).",
+	}, []string{"kind"})
+
+	checkErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "assistant_reminder_check_errors_total",
+		Help: "Reminder checks that couldn't load events, by kind (meeting, all_day).",
+	}, []string{"kind"})
+)
@@ -1,78 +1,267 @@
 package reminder
 
 import (
+	"context"
 	"fmt"
-	"log"
-	"strconv"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/robfig/cron/v3"
+	gcal "google.golang.org/api/calendar/v3"
 	"virtual-assistant/internal/bot"
 	"virtual-assistant/internal/calendar"
+	"virtual-assistant/internal/i18n"
+	"virtual-assistant/internal/logging"
+	"virtual-assistant/internal/markup"
+	"virtual-assistant/internal/storage"
+	"virtual-assistant/internal/timezone"
 )
 
+var logger = logging.For("reminder")
+
+// A check runs every 5 seconds; a loop that hasn't finished one for this long is stuck
+const maxTickAge = 30 * time.Second
+
+// How far ahead and back /reminders looks
+const listWindow = 24 * time.Hour
+
+// DefaultTiers sends a single reminder 10 minutes before a meeting.
+var DefaultTiers = []time.Duration{10 * time.Minute}
+
 type ReminderService struct {
-	calendarService *calendar.CalendarService
-	telegramBot     *bot.TelegramBot
-	cron            *cron.Cron
-	userChatID      int64
-	sentReminders   map[string]bool // Track sent reminders to prevent duplicates
-	reminderMutex   sync.RWMutex    // Protect the sentReminders map
+	calendarService  *calendar.CalendarService
+	telegramBot      *bot.TelegramBot
+	cron             *cron.Cron
+	userChatID       int64
+	db               *storage.DB
+	sentReminders    map[string]time.Time // Reminders already sent, with the time of their event; mirrors the database
+	allDayChecked    map[string]string    // Calendar → local date (YYYY-MM-DD) whose all-day events were looked up
+	reminderMutex    sync.RWMutex         // Protect the sentReminders and allDayChecked maps
+	scheduleMutex    sync.RWMutex         // Protects the settings below, which can change while running
+	allDayNotice     bool                 // Send a day-before notice for all-day events
+	allDayNoticeHour int                  // Local hour from which the notice is sent
+	quietFrom        int                  // Local hour from which nothing is sent
+	quietUntil       int                  // Local hour sending resumes; no quiet hours when equal to quietFrom
+	tiers            []time.Duration      // How long before a meeting reminders go out, shortest first
+	lastTick         atomic.Int64         // Unix nanoseconds when the last check finished, or Start was called
 }
 
-func NewReminderService(calendarService *calendar.CalendarService, telegramBot *bot.TelegramBot) *ReminderService {
-	// Create cron with seconds support
-	c := cron.New(cron.WithSeconds())
+func NewReminderService(calendarService *calendar.CalendarService, telegramBot *bot.TelegramBot, db *storage.DB) *ReminderService {
+	// Create cron with seconds support. A check still running when the next
+	// one is due makes that one skip, so a slow calendar can't pile them up.
+	c := cron.New(cron.WithSeconds(), cron.WithLogger(cronLogger{}), cron.WithChain(cron.SkipIfStillRunning(cronLogger{})))
 	return &ReminderService{
-		calendarService: calendarService,
-		telegramBot:     telegramBot,
-		cron:            c,
-		userChatID:      0,
-		sentReminders:   make(map[string]bool),
+		calendarService:  calendarService,
+		telegramBot:      telegramBot,
+		cron:             c,
+		userChatID:       0,
+		db:               db,
+		sentReminders:    make(map[string]time.Time),
+		allDayChecked:    make(map[string]string),
+		allDayNotice:     true,
+		allDayNoticeHour: 18,
+		tiers:            DefaultTiers,
 	}
 }
 
+// SetUserChatID makes chatID get reminders even if it hasn't messaged the
+// bot yet, e.g. on a fresh database.
 func (rs *ReminderService) SetUserChatID(chatID int64) {
 	rs.userChatID = chatID
 }
 
+// SetAllDayNotice configures the day-before notice that replaces the
+// minutes-before reminder for all-day events.
+func (rs *ReminderService) SetAllDayNotice(enabled bool, hour int) {
+	rs.scheduleMutex.Lock()
+	defer rs.scheduleMutex.Unlock()
+	rs.allDayNotice = enabled
+	rs.allDayNoticeHour = hour
+}
+
+// SetTiers sets how long before a meeting reminders are sent, one reminder
+// per tier, e.g. 30 and 10 minutes. Meetings already reminded for a tier
+// that is still listed aren't reminded again.
+func (rs *ReminderService) SetTiers(tiers []time.Duration) {
+	if len(tiers) == 0 {
+		tiers = DefaultTiers
+	}
+	sorted := append([]time.Duration(nil), tiers...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	rs.scheduleMutex.Lock()
+	defer rs.scheduleMutex.Unlock()
+	rs.tiers = sorted
+}
+
+// SetQuietHours stops reminders and notices from going out between the local
+// hours from and until, e.g. 22 and 7. A reminder that falls due meanwhile is
+// sent when quiet hours end if its meeting hasn't started yet. Equal hours
+// turn quiet hours off.
+func (rs *ReminderService) SetQuietHours(from, until int) {
+	rs.scheduleMutex.Lock()
+	defer rs.scheduleMutex.Unlock()
+	rs.quietFrom = from
+	rs.quietUntil = until
+}
+
+// quiet reports whether now is within quiet hours, which may span midnight.
+func (rs *ReminderService) quiet(now time.Time) bool {
+	rs.scheduleMutex.RLock()
+	from, until := rs.quietFrom, rs.quietUntil
+	rs.scheduleMutex.RUnlock()
+
+	hour := now.In(timezone.Location()).Hour()
+	switch {
+	case from == until:
+		return false
+	case from < until:
+		return hour >= from && hour < until
+	default:
+		return hour >= from || hour < until
+	}
+}
+
+func (rs *ReminderService) currentTiers() []time.Duration {
+	rs.scheduleMutex.RLock()
+	defer rs.scheduleMutex.RUnlock()
+	return rs.tiers
+}
+
+// tierFor returns the shortest tier the meeting is already within, so a
+// meeting first seen 8 minutes ahead only gets the 10-minute reminder.
+func tierFor(tiers []time.Duration, timeUntil time.Duration) (time.Duration, bool) {
+	for _, tier := range tiers {
+		if timeUntil <= tier {
+			return tier, true
+		}
+	}
+	return 0, false
+}
+
 func (rs *ReminderService) Start() error {
+	// Reminders sent before a restart aren't sent again
+	sent, err := rs.db.SentReminders()
+	if err != nil {
+		return fmt.Errorf("failed to load sent reminders: %v", err)
+	}
+	rs.reminderMutex.Lock()
+	for key, reminder := range sent {
+		rs.sentReminders[key] = reminder.EventTime
+	}
+	rs.reminderMutex.Unlock()
+	rs.cleanupOldReminders()
+
 	// Check every 5 seconds instead of 10 minutes
 	// Cron format: second minute hour day month weekday
-	_, err := rs.cron.AddFunc("*/5 * * * * *", rs.checkUpcomingMeetings)
+	_, err = rs.cron.AddFunc("*/5 * * * * *", rs.checkUpcomingMeetings)
 	if err != nil {
 		return fmt.Errorf("failed to add cron job: %v", err)
 	}
+	if _, err := rs.cron.AddFunc("0 0 * * * *", rs.cleanupOldReminders); err != nil {
+		return fmt.Errorf("failed to add cron job: %v", err)
+	}
 
+	rs.lastTick.Store(time.Now().UnixNano())
 	rs.cron.Start()
-	log.Println("Reminder service started - checking every 5 seconds")
+	logger.Info("reminder service started", "interval", "5s")
 	return nil
 }
 
-func (rs *ReminderService) Stop() {
-	rs.cron.Stop()
-	log.Println("Reminder service stopped")
+// Stop stops scheduling checks and waits for a running one to finish.
+func (rs *ReminderService) Stop(ctx context.Context) error {
+	select {
+	case <-rs.cron.Stop().Done():
+	case <-ctx.Done():
+		return fmt.Errorf("reminder check still running: %v", ctx.Err())
+	}
+	logger.Info("reminder service stopped")
+	return nil
+}
+
+// Health reports whether the reminder loop is still ticking.
+func (rs *ReminderService) Health(ctx context.Context) (string, error) {
+	last := rs.lastTick.Load()
+	if last == 0 {
+		return "", fmt.Errorf("reminder loop not started")
+	}
+	age := time.Since(time.Unix(0, last)).Round(time.Second)
+	if age > maxTickAge {
+		return "", fmt.Errorf("reminder loop last finished a check %s ago", age)
+	}
+	return fmt.Sprintf("last check finished %s ago", age), nil
 }
 
 func (rs *ReminderService) checkUpcomingMeetings() {
+	// Stored on the way out, so a check stuck on the calendar shows in Health
+	defer func() { rs.lastTick.Store(time.Now().UnixNano()) }()
+	if rs.quiet(time.Now()) {
+		return
+	}
+
 	// Get all chat IDs from the bot's storage
 	chatIDs := rs.telegramBot.GetAllChatIDs()
+	if rs.userChatID != 0 && !slices.Contains(chatIDs, rs.userChatID) {
+		chatIDs = append(chatIDs, rs.userChatID)
+	}
 	if len(chatIDs) == 0 {
 		return // Don't spam logs when no users
 	}
 
-	// Get events within the next 15 minutes (to catch 10-minute reminders)
-	events, err := rs.calendarService.GetUpcomingEvents(15 * time.Minute)
+	// Each calendar is looked up once for all the chats that selected it, so
+	// reminders follow the calendar and changing a selection doesn't repeat them
+	var calendarIDs []string
+	subscribers := make(map[string][]int64)
+	for _, chatID := range chatIDs {
+		for _, calendarID := range rs.telegramBot.CalendarsFor(chatID) {
+			if _, ok := subscribers[calendarID]; !ok {
+				calendarIDs = append(calendarIDs, calendarID)
+			}
+			subscribers[calendarID] = append(subscribers[calendarID], chatID)
+		}
+	}
+
+	delivered := make(deliveries)
+	for _, calendarID := range calendarIDs {
+		rs.checkCalendar(calendarID, subscribers[calendarID], delivered)
+	}
+}
+
+// deliveries records which chats got which meeting during one check, so a
+// meeting sitting on several of a chat's calendars reaches it once.
+type deliveries map[string]bool
+
+// first reports whether chatID hasn't had the meeting yet, and records it.
+func (d deliveries) first(chatID int64, event *gcal.Event, occurrence string) bool {
+	meeting := event.ICalUID
+	if meeting == "" {
+		meeting = event.Id
+	}
+	key := fmt.Sprintf("%d_%s_%s", chatID, meeting, occurrence)
+	if d[key] {
+		return false
+	}
+	d[key] = true
+	return true
+}
+
+func (rs *ReminderService) checkCalendar(calendarID string, chatIDs []int64, delivered deliveries) {
+	// Look a little past the longest tier so no meeting is missed
+	tiers := rs.currentTiers()
+	lookahead := tiers[len(tiers)-1] + 5*time.Minute
+	events, err := rs.calendarService.GetUpcomingEventsFrom([]string{calendarID}, lookahead)
 	if err != nil {
-		log.Printf("❌ Error getting upcoming events: %v", err)
+		logger.Error("failed to get upcoming events", "calendar_id", calendarID, "error", err)
+		checkErrors.WithLabelValues("meeting").Inc()
 		return
 	}
 
 
 	now := time.Now()
-	tenMinutesFromNow := now.Add(10 * time.Minute)
 	
 	// Count upcoming and past events
 	upcomingCount := 0
@@ -81,7 +270,7 @@ func (rs *ReminderService) checkUpcomingMeetings() {
 	// First pass to count events
 	for _, event := range events {
 		if event.Start.DateTime == "" {
-			continue
+			continue // All-day events are handled by checkAllDayEvents
 		}
 		eventTime, err := time.Parse(time.RFC3339, event.Start.DateTime)
 		if err != nil {
@@ -97,158 +286,317 @@ func (rs *ReminderService) checkUpcomingMeetings() {
 	
 	// Log the counts if there are any events
 	if upcomingCount > 0 || pastCount > 0 {
-		log.Printf("🔍 Found %d upcoming events and %d past events", upcomingCount, pastCount)
+		logger.Debug("checked upcoming events", "upcoming", upcomingCount, "past", pastCount)
 	}
 
 	for _, event := range events {
+		if calendar.IsAllDay(event) {
+			continue
+		}
 		if event.Start.DateTime == "" {
-			log.Printf("⚠️ Event '%s' has no start time", event.Summary)
+			logger.Warn("event has no start time", "event_id", event.Id, "title", event.Summary)
 			continue
 		}
 
 		eventTime, err := time.Parse(time.RFC3339, event.Start.DateTime)
 		if err != nil {
-			log.Printf("❌ Error parsing event time for '%s': %v", event.Summary, err)
+			logger.Error("failed to parse event time", "event_id", event.Id, "title", event.Summary, "error", err)
 			continue
 		}
 
-		// Create unique reminder key for this event
-		reminderKey := fmt.Sprintf("%s_%s", event.Id, eventTime.Format("2006-01-02T15:04"))
+		// Create unique reminder key for this calendar, event and tier
+		reminderKey := func(tier time.Duration) string {
+			return fmt.Sprintf("%s_%s_%s_%s", calendarID, event.Id, tier, eventTime.Format("2006-01-02T15:04"))
+		}
 		
 		// Debug: Log event details
 		timeUntilEvent := eventTime.Sub(now)
-		// Convert to Indonesia timezone for display
-		indonesiaLocation, _ := time.LoadLocation("Asia/Jakarta")
-		eventTimeLocal := eventTime.In(indonesiaLocation)
+		// Convert to the local timezone for display
+		loc := timezone.Location()
+		eventTimeLocal := eventTime.In(loc)
 		
 		// Check if event is in the past (negative time)
 		if eventTime.Before(now) {
 			// Only cleanup if exists in memory
+			var forgotten []string
 			rs.reminderMutex.Lock()
-			if _, exists := rs.sentReminders[reminderKey]; exists {
-				delete(rs.sentReminders, reminderKey)
-				log.Printf("🧹 Cleaned up memory for past event: '%s'", event.Summary)
+			for _, tier := range tiers {
+				if _, exists := rs.sentReminders[reminderKey(tier)]; exists {
+					delete(rs.sentReminders, reminderKey(tier))
+					forgotten = append(forgotten, reminderKey(tier))
+					logger.Debug("forgot reminder for past event", "event_id", event.Id, "title", event.Summary)
+				}
 			}
 			rs.reminderMutex.Unlock()
+			if err := rs.db.ForgetReminders(forgotten...); err != nil {
+				logger.Error("failed to forget sent reminders", "event_id", event.Id, "error", err)
+			}
 			continue // Skip past events
 		}
 		
-		log.Printf("📅 Event: '%s' in %s (at %s WIB)", event.Summary, formatDuration(timeUntilEvent), eventTimeLocal.Format("15:04"))
+		logger.Debug("upcoming event", "event_id", event.Id, "title", event.Summary, "starts_in", i18n.FormatDuration(i18n.English, timeUntilEvent), "at", eventTimeLocal.Format("15:04"))
 
-		// Send reminder if meeting is between 0-10 minutes away
-		if eventTime.Before(tenMinutesFromNow) {
-			log.Printf("🎯 Event '%s' is in reminder window (0-10 minutes)!", event.Summary)
+		// Send a reminder once the meeting is within one of the tiers
+		if tier, ok := tierFor(tiers, timeUntilEvent); ok {
+			logger.Debug("event is in the reminder window", "event_id", event.Id, "title", event.Summary, "tier", tier.String())
 			
 			// Check if already sent reminder
 			rs.reminderMutex.RLock()
-			alreadySent := rs.sentReminders[reminderKey]
+			_, alreadySent := rs.sentReminders[reminderKey(tier)]
 			rs.reminderMutex.RUnlock()
 			
 			if alreadySent {
-				log.Printf("⏭️ Reminder already sent for '%s' - skipping", event.Summary)
+				logger.Debug("reminder already sent", "event_id", event.Id, "title", event.Summary)
 				continue // Skip if already sent
 			}
 
 			timeUntil := eventTime.Sub(now)
-			message := fmt.Sprintf("🔔 **Meeting Reminder**\n\n📅 **%s**\n\n⏰ Starting in %s\n\n", 
-				event.Summary, 
-				formatDuration(timeUntil))
-
-			if event.Description != "" {
-				message += fmt.Sprintf("📝 %s\n\n", event.Description)
-			}
-
-			if event.Location != "" {
-				message += fmt.Sprintf("📍 %s\n\n", event.Location)
-			}
-
-			// Show attendees if any
-			if len(event.Attendees) > 0 {
-				var attendeeNames []string
-				for _, attendee := range event.Attendees {
-					if attendee.Email != "" {
-						attendeeNames = append(attendeeNames, attendee.Email)
-					}
-				}
-				if len(attendeeNames) > 0 {
-					message += fmt.Sprintf("👥 Attendees: %s\n\n", strings.Join(attendeeNames, ", "))
-				}
-			}
-
-			message += fmt.Sprintf("🕐 %s", eventTime.Format("15:04 MST"))
 
-			// Send reminder to all active users
+			// Send reminder to all active users, each in their own language
 			for _, chatID := range chatIDs {
-				log.Printf("🚀 Attempting to send reminder for '%s' to chat %d", event.Summary, chatID)
-				err = rs.telegramBot.SendReminder(chatID, message)
+				if !delivered.first(chatID, event, tier.String()+"_"+eventTime.Format(time.RFC3339)) {
+					continue
+				}
+				logger.Debug("sending reminder", "event_id", event.Id, "title", event.Summary, "chat_id", chatID)
+				message := formatReminder(rs.telegramBot.LanguageFor(chatID), event, eventTime, timeUntil)
+				err = rs.telegramBot.SendReminderWithJoin(chatID, message, calendar.JoinURL(event))
 				if err != nil {
-					log.Printf("❌ FAILED to send reminder to chat %d: %v", chatID, err)
+					logger.Error("failed to send reminder", "event_id", event.Id, "chat_id", chatID, "error", err)
+					remindersFailed.WithLabelValues("meeting").Inc()
 				} else {
-					log.Printf("✅ SUCCESS: Sent reminder for '%s' to chat %d", event.Summary, chatID)
+					logger.Info("sent reminder", "event_id", event.Id, "title", event.Summary, "chat_id", chatID)
+					remindersSent.WithLabelValues("meeting").Inc()
 				}
 			}
 
 			// Mark as sent to prevent duplicates
-			rs.reminderMutex.Lock()
-			rs.sentReminders[reminderKey] = true
-			log.Printf("💾 Saved reminder flag for '%s' in memory", event.Summary)
-			rs.reminderMutex.Unlock()
+			rs.markSent(reminderKey(tier), event.Summary, eventTime)
+			logger.Debug("marked reminder as sent", "event_id", event.Id, "tier", tier.String())
 		}
 	}
-	
+
+	rs.checkAllDayEvents(calendarID, chatIDs, delivered)
 }
 
-func formatDuration(d time.Duration) string {
-	if d < time.Minute {
-		return fmt.Sprintf("%d seconds", int(d.Seconds()))
+// checkAllDayEvents sends a single evening-before notice for all-day events
+// starting tomorrow, since "10 minutes before midnight" is useless for them.
+// Each calendar is looked up once a day, at the first check after the notice
+// hour.
+func (rs *ReminderService) checkAllDayEvents(calendarID string, chatIDs []int64, delivered deliveries) {
+	rs.scheduleMutex.RLock()
+	enabled, hour := rs.allDayNotice, rs.allDayNoticeHour
+	rs.scheduleMutex.RUnlock()
+	if !enabled {
+		return
 	}
-	if d < time.Hour {
-		return fmt.Sprintf("%d minutes", int(d.Minutes()))
+
+	loc := timezone.Location()
+	now := time.Now().In(loc)
+	if now.Hour() < hour {
+		return
 	}
-	hours := int(d.Hours())
-	minutes := int(d.Minutes()) % 60
-	if minutes == 0 {
-		return fmt.Sprintf("%d hour", hours)
+
+	today := now.Format("2006-01-02")
+	rs.reminderMutex.RLock()
+	checked := rs.allDayChecked[calendarID] == today
+	rs.reminderMutex.RUnlock()
+	if checked {
+		return
+	}
+
+	tomorrow := now.AddDate(0, 0, 1)
+	events, err := rs.calendarService.GetAllDayEventsStarting([]string{calendarID}, tomorrow)
+	if err != nil {
+		logger.Error("failed to get all-day events", "calendar_id", calendarID, "error", err)
+		checkErrors.WithLabelValues("all_day").Inc()
+		return // Try again on the next check
+	}
+
+	rs.reminderMutex.Lock()
+	rs.allDayChecked[calendarID] = today
+	rs.reminderMutex.Unlock()
+
+	for _, event := range events {
+		startDay, _, err := calendar.AllDayRange(event)
+		if err != nil {
+			logger.Error("failed to parse all-day event", "event_id", event.Id, "title", event.Summary, "error", err)
+			continue
+		}
+
+		reminderKey := fmt.Sprintf("%s_%s_%s", calendarID, event.Id, startDay.Format("2006-01-02T15:04"))
+
+		rs.reminderMutex.RLock()
+		_, alreadySent := rs.sentReminders[reminderKey]
+		rs.reminderMutex.RUnlock()
+		if alreadySent {
+			continue
+		}
+
+		for _, chatID := range chatIDs {
+			if !delivered.first(chatID, event, startDay.Format("2006-01-02")) {
+				continue
+			}
+			message := formatAllDayNotice(rs.telegramBot.LanguageFor(chatID), event, startDay)
+			if err := rs.telegramBot.SendReminder(chatID, message); err != nil {
+				logger.Error("failed to send all-day notice", "event_id", event.Id, "chat_id", chatID, "error", err)
+				remindersFailed.WithLabelValues("all_day").Inc()
+			} else {
+				logger.Info("sent all-day notice", "event_id", event.Id, "title", event.Summary, "chat_id", chatID)
+				remindersSent.WithLabelValues("all_day").Inc()
+			}
+		}
+
+		rs.markSent(reminderKey, event.Summary, startDay)
 	}
-	return fmt.Sprintf("%d hour %d minutes", hours, minutes)
 }
 
-func (rs *ReminderService) cleanupOldReminders() {
+// cronLogger passes the scheduler's messages to the package logger. Its
+// routine ones, such as skipped runs, are only worth seeing when debugging.
+type cronLogger struct{}
+
+func (cronLogger) Info(msg string, keysAndValues ...interface{}) {
+	logger.Debug(msg, keysAndValues...)
+}
+
+func (cronLogger) Error(err error, msg string, keysAndValues ...interface{}) {
+	logger.Error(msg, append(keysAndValues, "error", err)...)
+}
+
+// markSent records a sent reminder in memory and in the database.
+func (rs *ReminderService) markSent(key, title string, eventTime time.Time) {
 	rs.reminderMutex.Lock()
-	defer rs.reminderMutex.Unlock()
-	
-	// Clean up reminder keys older than 2 hours
-	cutoff := time.Now().Add(-2 * time.Hour)
-	cleanedCount := 0
-	for key := range rs.sentReminders {
-		// Extract timestamp from key (format: eventId_2006-01-02T15:04)
-		parts := strings.Split(key, "_")
-		if len(parts) >= 2 {
-			timeStr := parts[len(parts)-1]
-			if eventTime, err := time.Parse("2006-01-02T15:04", timeStr); err == nil {
-				if eventTime.Before(cutoff) {
-					delete(rs.sentReminders, key)
-					cleanedCount++
+	rs.sentReminders[key] = eventTime
+	rs.reminderMutex.Unlock()
+
+	if err := rs.db.MarkReminderSent(key, storage.SentReminder{Title: title, EventTime: eventTime}); err != nil {
+		logger.Error("failed to save sent reminder", "key", key, "error", err)
+	}
+}
+
+// SendTestReminder sends chatID the reminder for a made-up meeting starting
+// one tier from now, to check that reminders get through. Nothing is recorded
+// as sent.
+func (rs *ReminderService) SendTestReminder(chatID int64) error {
+	lead := DefaultTiers[0]
+	if tiers := rs.currentTiers(); len(tiers) > 0 {
+		lead = tiers[0]
+	}
+
+	lang := rs.telegramBot.LanguageFor(chatID)
+	event := &gcal.Event{Summary: i18n.T(lang, "reminder.test_title"), Description: i18n.T(lang, "reminder.test_description")}
+	return rs.telegramBot.SendReminder(chatID, formatReminder(lang, event, time.Now().Add(lead), lead))
+}
+
+// ListReminders returns the meeting reminders due in the next day and those
+// sent in the last day, for /reminders.
+func (rs *ReminderService) ListReminders(ctx context.Context) ([]bot.ReminderStatus, error) {
+	now := time.Now()
+	var reminders []bot.ReminderStatus
+
+	sent, err := rs.db.SentReminders()
+	if err != nil {
+		return nil, err
+	}
+	for _, reminder := range sent {
+		if now.Sub(reminder.SentAt) <= listWindow {
+			reminders = append(reminders, bot.ReminderStatus{Title: reminder.Title, EventTime: reminder.EventTime, Due: reminder.SentAt, Sent: true})
+		}
+	}
+
+	// Every calendar some chat follows, looked up once
+	var calendarIDs []string
+	for _, chatID := range rs.telegramBot.GetAllChatIDs() {
+		for _, calendarID := range rs.telegramBot.CalendarsFor(chatID) {
+			if !slices.Contains(calendarIDs, calendarID) {
+				calendarIDs = append(calendarIDs, calendarID)
+			}
+		}
+	}
+	if len(calendarIDs) > 0 {
+		events, err := rs.calendarService.GetUpcomingEventsFrom(calendarIDs, listWindow)
+		if err != nil {
+			return nil, err
+		}
+		tiers := rs.currentTiers()
+		for _, event := range events {
+			if calendar.IsAllDay(event) || event.Start.DateTime == "" {
+				continue
+			}
+			eventTime, err := time.Parse(time.RFC3339, event.Start.DateTime)
+			if err != nil {
+				continue
+			}
+			for _, tier := range tiers {
+				if due := eventTime.Add(-tier); due.After(now) {
+					reminders = append(reminders, bot.ReminderStatus{Title: event.Summary, EventTime: eventTime, Due: due})
 				}
 			}
 		}
 	}
-	if cleanedCount > 0 {
-		log.Printf("🧹 Cleaned up %d old reminder entries from memory", cleanedCount)
+
+	sort.Slice(reminders, func(i, j int) bool { return reminders[i].Due.Before(reminders[j].Due) })
+	return reminders, nil
+}
+
+func formatReminder(lang i18n.Language, event *gcal.Event, eventTime time.Time, timeUntil time.Duration) markup.HTML {
+	message := "📅 " + markup.Bold(event.Summary) + "\n\n"
+	message += markup.Sprintf("⏰ "+i18n.T(lang, "reminder.starting_in")+"\n\n", i18n.FormatDuration(lang, timeUntil))
+
+	if event.Description != "" {
+		message += markup.Sprintf("📝 %s\n\n", event.Description)
+	}
+
+	if event.Location != "" {
+		message += markup.Sprintf("📍 %s\n\n", event.Location)
+	}
+
+	// Show attendees if any
+	if len(event.Attendees) > 0 {
+		var attendeeNames []string
+		for _, attendee := range event.Attendees {
+			if attendee.Email != "" {
+				attendeeNames = append(attendeeNames, attendee.Email)
+			}
+		}
+		if len(attendeeNames) > 0 {
+			message += markup.Sprintf(i18n.T(lang, "reminder.attendees"), strings.Join(attendeeNames, ", "))
+		}
+	}
+
+	message += markup.Sprintf("🕐 %s", eventTime.Format("15:04 MST"))
+	return message
+}
+
+func formatAllDayNotice(lang i18n.Language, event *gcal.Event, startDay time.Time) markup.HTML {
+	message := markup.Sprintf(i18n.T(lang, "reminder.all_day"), i18n.FormatTime(lang, startDay, "Mon 02 Jan"), markup.Bold(event.Summary))
+	if _, total := calendar.AllDaySpan(event, startDay); total > 1 {
+		message += markup.Sprintf(i18n.T(lang, "reminder.lasts"), total)
+	}
+	if event.Location != "" {
+		message += markup.Sprintf("\n\n📍 %s", event.Location)
 	}
+	return message
 }
 
+// cleanupOldReminders forgets reminders for events that started more than 2
+// hours ago; they can't come up again. It runs hourly.
+func (rs *ReminderService) cleanupOldReminders() {
+	cutoff := time.Now().Add(-2 * time.Hour)
+	var stale []string
+	rs.reminderMutex.Lock()
+	for key, eventTime := range rs.sentReminders {
+		if eventTime.Before(cutoff) {
+			delete(rs.sentReminders, key)
+			stale = append(stale, key)
+		}
+	}
+	rs.reminderMutex.Unlock()
 
-func (rs *ReminderService) SetChatIDFromEnv(chatIDStr string) error {
-	if chatIDStr == "" {
-		return fmt.Errorf("chat ID not provided")
+	if err := rs.db.ForgetReminders(stale...); err != nil {
+		logger.Error("failed to forget old reminders", "error", err)
+		return
 	}
-	
-	chatID, err := strconv.ParseInt(chatIDStr, 10, 64)
-	if err != nil {
-		return fmt.Errorf("invalid chat ID format: %v", err)
+	if len(stale) > 0 {
+		logger.Debug("cleaned up old reminder entries", "count", len(stale))
 	}
-	
-	rs.SetUserChatID(chatID)
-	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,23 @@
+package rsvp
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	notificationsSent = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "assistant_rsvp_notifications_sent_total",
+		Help: "RSVP updates and invitation cards delivered, by kind (update, invitation).",
+	}, []string{"kind"})
+
+	notificationsFailed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "assistant_rsvp_notifications_failed_total",
+		Help: "RSVP updates and invitation cards that couldn't be delivered, by kind (update, invitation).",
+	}, []string{"kind"})
+
+	checkErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "assistant_rsvp_check_errors_total",
+		Help: "RSVP checks that couldn't load events, by kind (responses, invitations).",
+	}, []string{"kind"})
+)
@@ -0,0 +1,302 @@
+package rsvp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	gcal "google.golang.org/api/calendar/v3"
+	"virtual-assistant/internal/bot"
+	"virtual-assistant/internal/calendar"
+	"virtual-assistant/internal/i18n"
+	"virtual-assistant/internal/logging"
+	"virtual-assistant/internal/storage"
+	"virtual-assistant/internal/timezone"
+)
+
+var logger = logging.For("rsvp")
+
+const lookAheadDays = 30
+
+type attendeeState struct {
+	Status  string `json:"status"`
+	Comment string `json:"comment,omitempty"`
+}
+
+// rsvpUpdate is a change worth telling the user about, formatted per chat.
+type rsvpUpdate struct {
+	event    *gcal.Event
+	attendee *gcal.EventAttendee
+	declined bool // Otherwise the attendee left a new comment
+}
+
+type trackerState struct {
+	Responses   map[string]map[string]attendeeState `json:"responses"`   // event ID -> attendee email -> last seen response
+	Invitations map[string]bool                     `json:"invitations"` // Invitation event IDs we already sent a card for
+}
+
+// RSVPService watches attendee responses on events we organise and tells us
+// when someone declines or leaves a comment. The Calendar API doesn't expose
+// "propose a new time" suggestions directly; Google records them as the
+// attendee's comment, so comment changes are reported as possible proposals.
+// It also pushes invite cards for events we've been invited to.
+type RSVPService struct {
+	calendarService *calendar.CalendarService
+	telegramBot     *bot.TelegramBot
+	cron            *cron.Cron
+	db              *storage.DB
+	state           trackerState
+	stateMutex      sync.Mutex
+	initialCheck    sync.WaitGroup // The check Start runs straight away, outside cron
+}
+
+func NewRSVPService(calendarService *calendar.CalendarService, telegramBot *bot.TelegramBot, db *storage.DB) *RSVPService {
+	rs := &RSVPService{
+		calendarService: calendarService,
+		telegramBot:     telegramBot,
+		cron:            cron.New(cron.WithSeconds()),
+		db:              db,
+	}
+
+	data, err := db.RSVPState()
+	if err != nil {
+		logger.Warn("ignoring unreadable RSVP state", "error", err)
+	} else if data != nil {
+		if err := json.Unmarshal(data, &rs.state); err != nil {
+			logger.Warn("ignoring unreadable RSVP state", "error", err)
+			rs.state = trackerState{}
+		}
+	}
+	if rs.state.Responses == nil {
+		rs.state.Responses = make(map[string]map[string]attendeeState)
+	}
+	if rs.state.Invitations == nil {
+		rs.state.Invitations = make(map[string]bool)
+	}
+
+	return rs
+}
+
+func (rs *RSVPService) Start() error {
+	// Responses trickle in slowly, every 5 minutes is plenty
+	_, err := rs.cron.AddFunc("0 */5 * * * *", rs.checkResponses)
+	if err != nil {
+		return fmt.Errorf("failed to add cron job: %v", err)
+	}
+
+	// New invitations are worth answering quickly
+	_, err = rs.cron.AddFunc("30 * * * * *", rs.checkInvitations)
+	if err != nil {
+		return fmt.Errorf("failed to add cron job: %v", err)
+	}
+
+	rs.cron.Start()
+	logger.Info("RSVP tracker started", "responses_interval", "5m", "invitations_interval", "1m")
+
+	rs.initialCheck.Add(1)
+	go func() {
+		defer rs.initialCheck.Done()
+		rs.checkResponses()
+	}()
+	return nil
+}
+
+// Stop stops the checks, waits for a running one to finish and saves the
+// tracker state.
+func (rs *RSVPService) Stop(ctx context.Context) error {
+	stopped := make(chan struct{})
+	go func() {
+		<-rs.cron.Stop().Done()
+		rs.initialCheck.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		return fmt.Errorf("RSVP check still running: %v", ctx.Err())
+	}
+
+	rs.stateMutex.Lock()
+	rs.saveState()
+	rs.stateMutex.Unlock()
+
+	logger.Info("RSVP tracker stopped")
+	return nil
+}
+
+// ownerGroups groups the owners' chats by the calendars they follow, the
+// same selection /rsvp uses, so each set of calendars is looked up once.
+func (rs *RSVPService) ownerGroups() (map[string][]int64, map[string][]string) {
+	groups := make(map[string][]int64)
+	calendarSets := make(map[string][]string)
+	for _, chatID := range rs.telegramBot.OwnerChatIDs() {
+		calendarIDs := rs.telegramBot.CalendarsFor(chatID)
+		groupKey := strings.Join(calendarIDs, ",")
+		groups[groupKey] = append(groups[groupKey], chatID)
+		calendarSets[groupKey] = calendarIDs
+	}
+	return groups, calendarSets
+}
+
+// checkResponses tells the owners when an attendee of a meeting they
+// organise declines or comments. Nobody else is told who answered what.
+func (rs *RSVPService) checkResponses() {
+	groups, calendarSets := rs.ownerGroups()
+	if len(groups) == 0 {
+		return
+	}
+
+	rs.stateMutex.Lock()
+	defer rs.stateMutex.Unlock()
+
+	now := time.Now()
+	current := make(map[string]map[string]attendeeState)
+	sent := 0
+	for groupKey, chatIDs := range groups {
+		events, err := rs.calendarService.GetOrganizedEvents(calendarSets[groupKey], now, now.AddDate(0, 0, lookAheadDays))
+		if err != nil {
+			logger.Error("failed to get organised events", "error", err)
+			checkErrors.WithLabelValues("responses").Inc()
+			return // Keep the old state so nothing is missed next time
+		}
+
+		updates := rs.diffResponses(events, current)
+		for _, chatID := range chatIDs {
+			lang := rs.telegramBot.LanguageFor(chatID)
+			for _, update := range updates {
+				if err := rs.telegramBot.SendNotification(chatID, formatUpdate(lang, update)); err != nil {
+					logger.Error("failed to send RSVP update", "chat_id", chatID, "error", err)
+					notificationsFailed.WithLabelValues("update").Inc()
+					continue
+				}
+				notificationsSent.WithLabelValues("update").Inc()
+			}
+		}
+		sent += len(updates)
+	}
+
+	rs.state.Responses = current
+	rs.saveState()
+
+	if sent > 0 {
+		logger.Info("sent RSVP updates", "count", sent)
+	}
+}
+
+// diffResponses compares the attendees of events with the saved state,
+// recording what it sees in current, and returns the changes worth telling.
+func (rs *RSVPService) diffResponses(events []*gcal.Event, current map[string]map[string]attendeeState) []rsvpUpdate {
+	var updates []rsvpUpdate
+	for _, event := range events {
+		previous, known := rs.state.Responses[event.Id]
+		current[event.Id] = make(map[string]attendeeState)
+
+		for _, attendee := range event.Attendees {
+			if attendee.Self || attendee.Resource {
+				continue
+			}
+
+			latest := attendeeState{Status: attendee.ResponseStatus, Comment: attendee.Comment}
+			current[event.Id][attendee.Email] = latest
+
+			// The first time we see an event we only record where things stand
+			if !known {
+				continue
+			}
+			before := previous[attendee.Email]
+
+			if latest.Status == "declined" && before.Status != "declined" {
+				updates = append(updates, rsvpUpdate{event: event, attendee: attendee, declined: true})
+			} else if latest.Comment != "" && latest.Comment != before.Comment {
+				updates = append(updates, rsvpUpdate{event: event, attendee: attendee})
+			}
+		}
+	}
+	return updates
+}
+
+// checkInvitations sends a card for each new invitation on the calendars
+// the owners follow, to the owners only: theirs is the answer that counts.
+func (rs *RSVPService) checkInvitations() {
+	groups, calendarSets := rs.ownerGroups()
+	if len(groups) == 0 {
+		return
+	}
+
+	rs.stateMutex.Lock()
+	defer rs.stateMutex.Unlock()
+
+	now := time.Now()
+	pending := make(map[string]bool)
+	sent := 0
+	for groupKey, chatIDs := range groups {
+		events, err := rs.calendarService.GetPendingInvitations(calendarSets[groupKey], now, now.AddDate(0, 0, lookAheadDays))
+		if err != nil {
+			logger.Error("failed to get invitations", "error", err)
+			checkErrors.WithLabelValues("invitations").Inc()
+			return
+		}
+
+		for _, event := range events {
+			pending[event.Id] = true
+			if rs.state.Invitations[event.Id] {
+				continue
+			}
+
+			for _, chatID := range chatIDs {
+				if err := rs.telegramBot.SendInvitation(chatID, event); err != nil {
+					logger.Error("failed to send invitation card", "event_id", event.Id, "chat_id", chatID, "error", err)
+					notificationsFailed.WithLabelValues("invitation").Inc()
+					continue
+				}
+				notificationsSent.WithLabelValues("invitation").Inc()
+			}
+			sent++
+		}
+	}
+
+	// Forget invitations that were answered or are no longer upcoming
+	rs.state.Invitations = pending
+	rs.saveState()
+
+	if sent > 0 {
+		logger.Info("sent invitation cards", "count", sent)
+	}
+}
+
+func (rs *RSVPService) saveState() {
+	data, err := json.Marshal(rs.state)
+	if err != nil {
+		logger.Error("failed to marshal RSVP state", "error", err)
+		return
+	}
+	if err := rs.db.SaveRSVPState(data); err != nil {
+		logger.Error("failed to save RSVP state", "error", err)
+	}
+}
+
+func formatUpdate(lang i18n.Language, update rsvpUpdate) string {
+	name := calendar.AttendeeName(update.attendee)
+	if !update.declined {
+		return i18n.T(lang, "rsvp.comment_notice", name, update.event.Summary, formatStart(lang, update.event), update.attendee.Comment)
+	}
+
+	message := i18n.T(lang, "rsvp.declined_notice", name, update.event.Summary, formatStart(lang, update.event))
+	if update.attendee.Comment != "" {
+		message += fmt.Sprintf("\n\n💬 %s", update.attendee.Comment)
+	}
+	return message
+}
+
+func formatStart(lang i18n.Language, event *gcal.Event) string {
+	if t, err := time.Parse(time.RFC3339, event.Start.DateTime); err == nil {
+		loc := timezone.Location()
+		return i18n.FormatTime(lang, t.In(loc), "Mon 02 Jan 15:04")
+	}
+	return event.Start.Date
+}
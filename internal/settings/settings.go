@@ -0,0 +1,135 @@
+package settings
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"virtual-assistant/internal/logging"
+	"virtual-assistant/internal/storage"
+)
+
+var logger = logging.For("settings")
+
+// CalendarRule sends new events whose title or description mentions Keyword
+// to CalendarID, e.g. "work" -> the Work calendar.
+type CalendarRule struct {
+	Keyword    string `json:"keyword"`
+	CalendarID string `json:"calendar_id"`
+}
+
+type UserSettings struct {
+	Calendars       []string       `json:"calendars,omitempty"`        // Calendars included in agendas and reminders
+	DefaultCalendar string         `json:"default_calendar,omitempty"` // Where new events go when no rule matches
+	CalendarRules   []CalendarRule `json:"calendar_rules,omitempty"`
+	Language        string         `json:"language,omitempty"` // "en" or "id"; set by /lang or from Telegram
+}
+
+// ActiveCalendars returns the calendars to read from, falling back to the
+// primary calendar when the user hasn't chosen any.
+func (us *UserSettings) ActiveCalendars() []string {
+	if len(us.Calendars) == 0 {
+		return []string{"primary"}
+	}
+	return us.Calendars
+}
+
+// TargetCalendar picks the calendar for a new event: first matching rule,
+// then the user's default, then primary.
+func (us *UserSettings) TargetCalendar(title, description string) string {
+	text := strings.ToLower(title + " " + description)
+	for _, rule := range us.CalendarRules {
+		if strings.Contains(text, strings.ToLower(rule.Keyword)) {
+			return rule.CalendarID
+		}
+	}
+	if us.DefaultCalendar != "" {
+		return us.DefaultCalendar
+	}
+	return "primary"
+}
+
+// Store keeps per-chat settings in the database, keyed by chat ID, with a
+// copy in memory for reads.
+type Store struct {
+	db    *storage.DB
+	mutex sync.Mutex
+	users map[int64]*UserSettings
+}
+
+func NewStore(db *storage.DB) (*Store, error) {
+	store := &Store{
+		db:    db,
+		users: make(map[int64]*UserSettings),
+	}
+
+	all, err := db.AllSettings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read settings: %v", err)
+	}
+	for chatID, data := range all {
+		us := &UserSettings{}
+		if err := json.Unmarshal(data, us); err != nil {
+			return nil, fmt.Errorf("failed to parse settings of chat %d: %v", chatID, err)
+		}
+		store.users[chatID] = us
+	}
+
+	return store, nil
+}
+
+// Get returns a copy of the chat's settings; missing chats get defaults.
+func (s *Store) Get(chatID int64) UserSettings {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if us, exists := s.users[chatID]; exists {
+		return *us
+	}
+	return UserSettings{}
+}
+
+// Update applies fn to the chat's settings and persists the result.
+func (s *Store) Update(chatID int64, fn func(us *UserSettings)) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	us, exists := s.users[chatID]
+	if !exists {
+		us = &UserSettings{}
+		s.users[chatID] = us
+	}
+	fn(us)
+
+	return s.save(chatID, us)
+}
+
+// Flush writes every chat's settings to the database. Changes are saved as
+// they're made, so this only matters if an earlier save failed; it's called
+// on shutdown.
+func (s *Store) Flush() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var errs []error
+	for chatID, us := range s.users {
+		errs = append(errs, s.save(chatID, us))
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Store) save(chatID int64, us *UserSettings) error {
+	data, err := json.Marshal(us)
+	if err != nil {
+		return fmt.Errorf("failed to marshal settings: %v", err)
+	}
+
+	if err := s.db.SaveSettings(chatID, data); err != nil {
+		return fmt.Errorf("failed to save settings: %v", err)
+	}
+
+	logger.Debug("saved user settings", "chat_id", chatID)
+	return nil
+}
@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"sort"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Chat is a Telegram chat that has messaged the bot. Every known chat gets
+// reminders.
+type Chat struct {
+	ID        int64     `json:"id"`
+	Type      string    `json:"type,omitempty"` // private, group, supergroup or channel
+	Title     string    `json:"title"`          // Group title, or the user's first name for private chats
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// User is a Telegram user who has messaged the bot.
+type User struct {
+	ID           int64     `json:"id"`
+	FirstName    string    `json:"first_name"`
+	LastName     string    `json:"last_name,omitempty"`
+	Username     string    `json:"username,omitempty"`
+	LanguageCode string    `json:"language_code,omitempty"`
+	FirstSeen    time.Time `json:"first_seen"`
+	LastSeen     time.Time `json:"last_seen"`
+}
+
+// RecordActivity saves the chat a message came from and, if known, its
+// sender, keeping when each was first seen.
+func (db *DB) RecordActivity(chat Chat, user *User) error {
+	now := time.Now()
+	return db.bolt.Update(func(tx *bolt.Tx) error {
+		chats := tx.Bucket(chatsBucket)
+		var saved Chat
+		if _, err := get(chats, idKey(chat.ID), &saved); err != nil {
+			return err
+		}
+		chat.FirstSeen, chat.LastSeen = firstSeen(saved.FirstSeen, now), now
+		if err := put(chats, idKey(chat.ID), chat); err != nil {
+			return err
+		}
+
+		if user == nil {
+			return nil
+		}
+		users := tx.Bucket(usersBucket)
+		var savedUser User
+		if _, err := get(users, idKey(user.ID), &savedUser); err != nil {
+			return err
+		}
+		record := *user
+		record.FirstSeen, record.LastSeen = firstSeen(savedUser.FirstSeen, now), now
+		return put(users, idKey(user.ID), record)
+	})
+}
+
+func firstSeen(saved, now time.Time) time.Time {
+	if saved.IsZero() {
+		return now
+	}
+	return saved
+}
+
+// Chats returns every known chat, by ID.
+func (db *DB) Chats() ([]Chat, error) {
+	var chats []Chat
+	err := db.bolt.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(chatsBucket).ForEach(func(k, v []byte) error {
+			var chat Chat
+			if err := decode(v, &chat); err != nil {
+				return err
+			}
+			chats = append(chats, chat)
+			return nil
+		})
+	})
+	sort.Slice(chats, func(i, j int) bool { return chats[i].ID < chats[j].ID })
+	return chats, err
+}
+
+// ChatIDs returns the IDs of every known chat.
+func (db *DB) ChatIDs() ([]int64, error) {
+	var ids []int64
+	err := db.bolt.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(chatsBucket).ForEach(func(k, v []byte) error {
+			id, err := parseID(k)
+			if err != nil {
+				return err
+			}
+			ids = append(ids, id)
+			return nil
+		})
+	})
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, err
+}
+
+// Users returns every known user, by ID.
+func (db *DB) Users() ([]User, error) {
+	var users []User
+	err := db.bolt.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(usersBucket).ForEach(func(k, v []byte) error {
+			var user User
+			if err := decode(v, &user); err != nil {
+				return err
+			}
+			users = append(users, user)
+			return nil
+		})
+	})
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users, err
+}
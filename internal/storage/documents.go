@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"encoding/json"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// The contact book and the RSVP tracker's state are each kept whole, as the
+// package that owns them encoded them, under a single key of their bucket.
+var documentKey = []byte("current")
+
+// ContactBook returns the contact book, or nil when none was saved yet.
+func (db *DB) ContactBook() (json.RawMessage, error) {
+	return db.document(contactsBucket)
+}
+
+// SaveContactBook replaces the contact book.
+func (db *DB) SaveContactBook(data json.RawMessage) error {
+	return db.saveDocument(contactsBucket, data)
+}
+
+// RSVPState returns what the RSVP tracker last saw, or nil when it saved
+// nothing yet.
+func (db *DB) RSVPState() (json.RawMessage, error) {
+	return db.document(rsvpBucket)
+}
+
+// SaveRSVPState replaces the RSVP tracker's state.
+func (db *DB) SaveRSVPState(data json.RawMessage) error {
+	return db.saveDocument(rsvpBucket, data)
+}
+
+func (db *DB) document(bucket []byte) (json.RawMessage, error) {
+	var data json.RawMessage
+	err := db.bolt.View(func(tx *bolt.Tx) error {
+		_, err := get(tx.Bucket(bucket), documentKey, &data)
+		return err
+	})
+	return data, err
+}
+
+func (db *DB) saveDocument(bucket []byte, data json.RawMessage) error {
+	return db.bolt.Update(func(tx *bolt.Tx) error {
+		return put(tx.Bucket(bucket), documentKey, data)
+	})
+}
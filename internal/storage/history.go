@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"encoding/binary"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Message is one message of a conversation with the bot.
+type Message struct {
+	Role string    `json:"role"` // "user" or "assistant"
+	Text string    `json:"text"` // As sent; HTML for the assistant's replies
+	At   time.Time `json:"at"`
+}
+
+// SetHistoryLimit sets how many messages are kept per chat; older ones are
+// deleted as new ones arrive. Zero stops recording history.
+func (db *DB) SetHistoryLimit(limit int) {
+	db.historyLimit.Store(int64(limit))
+}
+
+// AppendHistory adds messages to the chat's history, then trims it to the
+// limit.
+func (db *DB) AppendHistory(chatID int64, messages ...Message) error {
+	limit := int(db.historyLimit.Load())
+	if limit <= 0 || len(messages) == 0 {
+		return nil
+	}
+
+	return db.bolt.Update(func(tx *bolt.Tx) error {
+		b, err := tx.Bucket(historyBucket).CreateBucketIfNotExists(idKey(chatID))
+		if err != nil {
+			return err
+		}
+		for _, message := range messages {
+			seq, err := b.NextSequence()
+			if err != nil {
+				return err
+			}
+			if err := put(b, seqKey(seq), message); err != nil {
+				return err
+			}
+		}
+
+		// Keys are in sequence order, so the oldest come first
+		var keys [][]byte
+		c := b.Cursor()
+		for k, _ := c.First(); k != nil; k, _ = c.Next() {
+			keys = append(keys, append([]byte(nil), k...))
+		}
+		for ; len(keys) > limit; keys = keys[1:] {
+			if err := b.Delete(keys[0]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// History returns up to the last n messages of the chat, oldest first.
+func (db *DB) History(chatID int64, n int) ([]Message, error) {
+	var messages []Message
+	err := db.bolt.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(historyBucket).Bucket(idKey(chatID))
+		if b == nil {
+			return nil
+		}
+		c := b.Cursor()
+		for k, v := c.Last(); k != nil && len(messages) < n; k, v = c.Prev() {
+			var message Message
+			if err := decode(v, &message); err != nil {
+				return err
+			}
+			messages = append(messages, message)
+		}
+		return nil
+	})
+
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
+	return messages, err
+}
+
+func seqKey(seq uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, seq)
+	return key
+}
@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+	"virtual-assistant/internal/crypt"
+)
+
+// ImportChatIDs copies the chats from a chat_ids.json written by earlier
+// versions. Like every import it runs once per database, on first start;
+// later calls return 0.
+func (db *DB) ImportChatIDs(path string) (int, error) {
+	return db.importOnce("chat_ids", path, func(tx *bolt.Tx, data []byte) (int, error) {
+		var legacy struct {
+			ChatIDs map[int64]string `json:"chat_ids"` // chatID -> user first name
+		}
+		if err := json.Unmarshal(data, &legacy); err != nil {
+			return 0, err
+		}
+
+		chats := tx.Bucket(chatsBucket)
+		now := time.Now()
+		for id, name := range legacy.ChatIDs {
+			if chats.Get(idKey(id)) != nil {
+				continue
+			}
+			chat := Chat{ID: id, Title: name, FirstSeen: now, LastSeen: now}
+			if err := put(chats, idKey(id), chat); err != nil {
+				return 0, err
+			}
+		}
+		return len(legacy.ChatIDs), nil
+	})
+}
+
+// ImportSettings copies the per-chat settings from a user_settings.json
+// written by earlier versions.
+func (db *DB) ImportSettings(path string) (int, error) {
+	return db.importOnce("settings", path, func(tx *bolt.Tx, data []byte) (int, error) {
+		var legacy map[int64]json.RawMessage
+		if err := json.Unmarshal(data, &legacy); err != nil {
+			return 0, err
+		}
+
+		settings := tx.Bucket(settingsBucket)
+		for id, value := range legacy {
+			if settings.Get(idKey(id)) != nil {
+				continue
+			}
+			if err := put(settings, idKey(id), value); err != nil {
+				return 0, err
+			}
+		}
+		return len(legacy), nil
+	})
+}
+
+// ImportContacts copies the contact book from a contacts.json written by
+// earlier versions.
+func (db *DB) ImportContacts(path string) (int, error) {
+	return db.importOnce("contacts", path, func(tx *bolt.Tx, data []byte) (int, error) {
+		var legacy struct {
+			Contacts []json.RawMessage `json:"contacts"`
+		}
+		if err := json.Unmarshal(data, &legacy); err != nil {
+			return 0, err
+		}
+		return len(legacy.Contacts), importDocument(tx.Bucket(contactsBucket), data)
+	})
+}
+
+// ImportRSVPState copies the RSVP tracker's state from an rsvp_state.json
+// written by earlier versions.
+func (db *DB) ImportRSVPState(path string) (int, error) {
+	return db.importOnce("rsvp_state", path, func(tx *bolt.Tx, data []byte) (int, error) {
+		var legacy struct {
+			Responses map[string]json.RawMessage `json:"responses"`
+		}
+		if err := json.Unmarshal(data, &legacy); err != nil {
+			return 0, err
+		}
+		return len(legacy.Responses), importDocument(tx.Bucket(rsvpBucket), data)
+	})
+}
+
+// importDocument stores a legacy file's contents unless something newer was
+// saved already.
+func importDocument(b *bolt.Bucket, data []byte) error {
+	if b.Get(documentKey) != nil {
+		return nil
+	}
+	return put(b, documentKey, json.RawMessage(data))
+}
+
+// importOnce runs fn on the contents of path unless an import called name
+// already happened. A missing file counts as imported, so a file appearing
+// later isn't mistaken for current data. The file itself is left in place.
+func (db *DB) importOnce(name, path string, fn func(tx *bolt.Tx, data []byte) (int, error)) (int, error) {
+	key := []byte("imported_" + name)
+	var count int
+	err := db.bolt.Update(func(tx *bolt.Tx) error {
+		meta := tx.Bucket(metaBucket)
+		if meta.Get(key) != nil {
+			return nil
+		}
+
+		data, err := crypt.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if err == nil {
+			if count, err = fn(tx, data); err != nil {
+				return fmt.Errorf("%s: %v", path, err)
+			}
+			logger.Info("imported legacy file", "file", path, "entries", count)
+		}
+		return meta.Put(key, []byte(time.Now().UTC().Format(time.RFC3339)))
+	})
+	return count, err
+}
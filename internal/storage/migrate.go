@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"fmt"
+	"strconv"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var schemaVersionKey = []byte("schema_version")
+
+// A migration moves the schema from version-1 to version. Migrations only
+// ever get appended; an applied one is never changed.
+type migration struct {
+	version     int
+	description string
+	up          func(tx *bolt.Tx) error
+}
+
+var migrations = []migration{
+	{1, "create chats, users, settings, reminders and history", func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{chatsBucket, usersBucket, settingsBucket, remindersBucket, historyBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	}},
+	{2, "create roles", func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(rolesBucket)
+		return err
+	}},
+	{3, "create contacts and rsvp", func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{contactsBucket, rsvpBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	}},
+}
+
+// migrate applies every migration newer than the database in one
+// transaction, so a failure leaves the schema as it was.
+func (db *DB) migrate() error {
+	return db.bolt.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(metaBucket); err != nil {
+			return err
+		}
+		current, err := schemaVersion(tx)
+		if err != nil {
+			return err
+		}
+		latest := migrations[len(migrations)-1].version
+		if current > latest {
+			return fmt.Errorf("database schema v%d is newer than this build supports (v%d)", current, latest)
+		}
+
+		for _, m := range migrations {
+			if m.version <= current {
+				continue
+			}
+			if err := m.up(tx); err != nil {
+				return fmt.Errorf("migration %d (%s) failed: %v", m.version, m.description, err)
+			}
+			logger.Info("applied database migration", "version", m.version, "migration", m.description)
+			current = m.version
+		}
+		return tx.Bucket(metaBucket).Put(schemaVersionKey, []byte(strconv.Itoa(current)))
+	})
+}
+
+func schemaVersion(tx *bolt.Tx) (int, error) {
+	value := tx.Bucket(metaBucket).Get(schemaVersionKey)
+	if value == nil {
+		return 0, nil
+	}
+	version, err := strconv.Atoi(string(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid schema version %q", value)
+	}
+	return version, nil
+}
@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// SentReminder is a reminder that went out.
+type SentReminder struct {
+	Title     string    `json:"title"`
+	EventTime time.Time `json:"event_time"`
+	SentAt    time.Time `json:"sent_at"`
+}
+
+// SentReminders returns the reminders already sent by key, so a restart
+// doesn't send them again.
+func (db *DB) SentReminders() (map[string]SentReminder, error) {
+	sent := make(map[string]SentReminder)
+	err := db.bolt.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(remindersBucket).ForEach(func(k, v []byte) error {
+			var reminder SentReminder
+			if err := decode(v, &reminder); err != nil {
+				return err
+			}
+			sent[string(k)] = reminder
+			return nil
+		})
+	})
+	return sent, err
+}
+
+// MarkReminderSent records that the reminder under key went out now.
+func (db *DB) MarkReminderSent(key string, reminder SentReminder) error {
+	reminder.SentAt = time.Now()
+	return db.bolt.Update(func(tx *bolt.Tx) error {
+		return put(tx.Bucket(remindersBucket), []byte(key), reminder)
+	})
+}
+
+// ForgetReminders deletes the given reminder keys.
+func (db *DB) ForgetReminders(keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return db.bolt.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(remindersBucket)
+		for _, key := range keys {
+			if err := b.Delete([]byte(key)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
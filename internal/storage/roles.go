@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Role is what a user may do with the bot. Owners come from the config and
+// are never stored; everyone without a stored role is a user.
+type Role string
+
+const (
+	RoleBanned Role = "banned"
+	RoleUser   Role = "user"
+	RoleAdmin  Role = "admin"
+	RoleOwner  Role = "owner"
+)
+
+// AtLeast reports whether r grants everything min does.
+func (r Role) AtLeast(min Role) bool {
+	return r.rank() >= min.rank()
+}
+
+// Outranks reports whether r grants more than other does.
+func (r Role) Outranks(other Role) bool {
+	return r.rank() > other.rank()
+}
+
+func (r Role) rank() int {
+	switch r {
+	case RoleBanned:
+		return 0
+	case RoleAdmin:
+		return 2
+	case RoleOwner:
+		return 3
+	}
+	return 1
+}
+
+// Role returns the stored role of a user.
+func (db *DB) Role(userID int64) (Role, error) {
+	role := RoleUser
+	err := db.bolt.View(func(tx *bolt.Tx) error {
+		_, err := get(tx.Bucket(rolesBucket), idKey(userID), &role)
+		return err
+	})
+	return role, err
+}
+
+// SetRole stores a user's role. Setting RoleUser clears it.
+func (db *DB) SetRole(userID int64, role Role) error {
+	if role == RoleOwner {
+		return fmt.Errorf("owners are set in the config")
+	}
+	return db.bolt.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(rolesBucket)
+		if role == RoleUser {
+			return b.Delete(idKey(userID))
+		}
+		return put(b, idKey(userID), role)
+	})
+}
+
+// Roles returns every stored role by user ID.
+func (db *DB) Roles() (map[int64]Role, error) {
+	roles := make(map[int64]Role)
+	err := db.bolt.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(rolesBucket).ForEach(func(k, v []byte) error {
+			id, err := parseID(k)
+			if err != nil {
+				return err
+			}
+			var role Role
+			if err := decode(v, &role); err != nil {
+				return err
+			}
+			roles[id] = role
+			return nil
+		})
+	})
+	return roles, err
+}
@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"encoding/json"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// AllSettings returns the settings of every chat that has any, as the
+// settings package encoded them.
+func (db *DB) AllSettings() (map[int64]json.RawMessage, error) {
+	all := make(map[int64]json.RawMessage)
+	err := db.bolt.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(settingsBucket).ForEach(func(k, v []byte) error {
+			id, err := parseID(k)
+			if err != nil {
+				return err
+			}
+			var data json.RawMessage
+			if err := decode(v, &data); err != nil {
+				return err
+			}
+			all[id] = data
+			return nil
+		})
+	})
+	return all, err
+}
+
+// SaveSettings replaces the settings of one chat.
+func (db *DB) SaveSettings(chatID int64, data json.RawMessage) error {
+	return db.bolt.Update(func(tx *bolt.Tx) error {
+		return put(tx.Bucket(settingsBucket), idKey(chatID), data)
+	})
+}
@@ -0,0 +1,201 @@
+// Package storage keeps the assistant's state in an embedded bbolt database:
+// users and chats and their roles, per-chat settings, sent reminders,
+// conversation history, the contact book and RSVP tracking. Values are JSON, encrypted with the storage key when
+// one is set.
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+	"virtual-assistant/internal/crypt"
+	"virtual-assistant/internal/logging"
+)
+
+var logger = logging.For("storage")
+
+// DefaultHistoryLimit is how many messages are kept per chat.
+const DefaultHistoryLimit = 50
+
+// How long Open waits for another process holding the database
+const lockTimeout = time.Second
+
+// ErrInUse is returned by Open when another process has the database open.
+var ErrInUse = errors.New("in use by another process")
+
+var (
+	metaBucket      = []byte("meta")
+	chatsBucket     = []byte("chats")
+	usersBucket     = []byte("users")
+	settingsBucket  = []byte("settings")
+	remindersBucket = []byte("reminders")
+	historyBucket   = []byte("history")
+	rolesBucket     = []byte("roles")
+	contactsBucket  = []byte("contacts")
+	rsvpBucket      = []byte("rsvp")
+)
+
+// DB is the assistant's database. It is safe for concurrent use; writes are
+// serialized by bbolt.
+type DB struct {
+	bolt         *bolt.DB
+	path         string
+	historyLimit atomic.Int64
+}
+
+// Open opens the database at path, creating it if needed, and brings its
+// schema up to date.
+func Open(path string) (*DB, error) {
+	b, err := bolt.Open(path, 0600, &bolt.Options{Timeout: lockTimeout})
+	if errors.Is(err, bolt.ErrTimeout) {
+		return nil, fmt.Errorf("%s: %w", path, ErrInUse)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %v", err)
+	}
+
+	db := &DB{bolt: b, path: path}
+	db.historyLimit.Store(DefaultHistoryLimit)
+	if err := db.migrate(); err != nil {
+		b.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// Close closes the database. Writes are committed as they're made, so there
+// is nothing to flush.
+func (db *DB) Close() error {
+	return db.bolt.Close()
+}
+
+// Path returns the database file.
+func (db *DB) Path() string {
+	return db.path
+}
+
+// Health reports the schema version and how many chats are known.
+func (db *DB) Health(ctx context.Context) (string, error) {
+	var version, chats int
+	err := db.bolt.View(func(tx *bolt.Tx) error {
+		var err error
+		if version, err = schemaVersion(tx); err != nil {
+			return err
+		}
+		chats = tx.Bucket(chatsBucket).Stats().KeyN
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("schema v%d, %d chats", version, chats), nil
+}
+
+// Reencrypt seals every value again with the current key, after a key
+// rotation or when encryption is first turned on. It returns how many values
+// were rewritten.
+func (db *DB) Reencrypt() (int, error) {
+	count := 0
+	err := db.bolt.Update(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+			if string(name) == string(metaBucket) {
+				return nil
+			}
+			n, err := reencrypt(b)
+			count += n
+			return err
+		})
+	})
+	return count, err
+}
+
+func reencrypt(b *bolt.Bucket) (int, error) {
+	// Values can't be changed while iterating, so collect them first
+	stale := make(map[string][]byte)
+	var nested [][]byte
+	err := b.ForEach(func(k, v []byte) error {
+		if v == nil {
+			nested = append(nested, append([]byte(nil), k...))
+		} else if !crypt.Sealed(v) {
+			stale[string(k)] = append([]byte(nil), v...)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for k, v := range stale {
+		plaintext, err := crypt.Open(v)
+		if err != nil {
+			return count, fmt.Errorf("%s: %w", k, err)
+		}
+		sealed, err := crypt.Seal(plaintext)
+		if err != nil {
+			return count, err
+		}
+		if err := b.Put([]byte(k), sealed); err != nil {
+			return count, err
+		}
+		count++
+	}
+	for _, k := range nested {
+		n, err := reencrypt(b.Bucket(k))
+		count += n
+		if err != nil {
+			return count, err
+		}
+	}
+	return count, nil
+}
+
+// put stores v as sealed JSON.
+func put(b *bolt.Bucket, key []byte, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	sealed, err := crypt.Seal(data)
+	if err != nil {
+		return err
+	}
+	return b.Put(key, sealed)
+}
+
+// get reads the value stored by put into v. It returns false if there is no
+// value under key.
+func get(b *bolt.Bucket, key []byte, v interface{}) (bool, error) {
+	data := b.Get(key)
+	if data == nil {
+		return false, nil
+	}
+	if err := decode(data, v); err != nil {
+		return false, fmt.Errorf("%s: %w", key, err)
+	}
+	return true, nil
+}
+
+func decode(data []byte, v interface{}) error {
+	plaintext, err := crypt.Open(data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(plaintext, v)
+}
+
+// idKey is the key chats and users are stored under. Group chat IDs are
+// negative, so they're kept in decimal rather than as big-endian integers.
+func idKey(id int64) []byte {
+	return []byte(strconv.FormatInt(id, 10))
+}
+
+func parseID(key []byte) (int64, error) {
+	return strconv.ParseInt(string(key), 10, 64)
+}
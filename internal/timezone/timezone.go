@@ -0,0 +1,43 @@
+// Package timezone holds the timezone the assistant works in: the one used
+// to read times users write, show event times and decide what "today" is.
+package timezone
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	// Embedded so the timezone can be loaded in containers without tzdata
+	_ "time/tzdata"
+)
+
+// Default is used until Set is called.
+const Default = "Asia/Jakarta"
+
+var current atomic.Pointer[time.Location]
+
+func init() {
+	if err := Set(Default); err != nil {
+		panic(err)
+	}
+}
+
+// Set switches to the IANA timezone name, e.g. "Asia/Jakarta".
+func Set(name string) error {
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return fmt.Errorf("unknown timezone %q: %v", name, err)
+	}
+	current.Store(location)
+	return nil
+}
+
+// Location returns the current timezone.
+func Location() *time.Location {
+	return current.Load()
+}
+
+// Name returns the current timezone's IANA name.
+func Name() string {
+	return current.Load().String()
+}